@@ -1,39 +1,66 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
 
 	"github.com/rafi/jig/internal/cli"
+	"github.com/rafi/jig/internal/logging"
 	"github.com/rafi/jig/pkg/client"
 	"github.com/rafi/jig/pkg/shell"
 )
 
-// newLogger creates a new logger instance.
-func newLogger(path string) *log.Logger {
-	logPath := filepath.Join(path, "jig.log")
-	logFile, err := os.Create(logPath)
-	if err != nil {
-		log.Fatal(err)
+// newLogger creates the logger of the options. Debugging logs all commands,
+// to the default log file unless another is given.
+func newLogger(opts client.Options) (*slog.Logger, func() error, error) {
+	logOpts := logging.Options{
+		Path:   opts.LogFile,
+		Level:  opts.LogLevel,
+		Format: opts.LogFormat,
+	}
+	if opts.Debug {
+		if logOpts.Path == "" {
+			logOpts.Path = logging.DefaultPath()
+		}
+		if logOpts.Level == "" {
+			logOpts.Level = "debug"
+		}
 	}
-	return log.New(logFile, "", 0)
+	return logging.New(logOpts)
 }
 
 // main instantiates app and parse arguments.
 func main() {
 	os.Args = cli.ShimArgs(os.Args)
-	cli, ctx := cli.NewApp()
-	var logger *log.Logger
-	if cli.Debug {
-		logger = newLogger(filepath.Join(os.Getenv("HOME"), ".cache"))
+	app, ctx := cli.NewApp()
+	logger, closeLog, err := newLogger(app.Options)
+	if err != nil {
+		ctx.FatalIfErrorf(err)
 	}
+	defer closeLog()
 	cmd := shell.DefaultCommander{Logger: logger}
 
-	jig, err := client.New(cli.Options, cmd)
-	if err != nil {
-		log.Fatal(err)
+	jig := client.New(app.Options, cmd)
+
+	// An interrupt stops running commands and waits, and a second one
+	// terminates jig at once.
+	interrupted, stop := signal.NotifyContext(
+		context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-interrupted.Done()
+		stop()
+	}()
+	jig.Context = interrupted
+	jig.Logger = logger
+
+	if err := ctx.Run(jig); err != nil {
+		logger.Error("failed", "command", ctx.Command(), "err", err)
+		closeLog()
+		ctx.Errorf("%s", err)
+		ctx.Exit(cli.ExitCode(err))
 	}
-	err = ctx.Run(jig)
-	ctx.FatalIfErrorf(err)
 }
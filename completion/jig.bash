@@ -3,8 +3,8 @@
 
 _jig() {
 	local cur="${COMP_WORDS[COMP_CWORD]}"
-	local cmds='start stop print list edit new switch version'
-	local opts=$'--file --detach --debug --inside --help'
+	local cmds='start stop print list edit new switch import export version'
+	local opts=$'--file --detach --debug --inside --socket-name --socket-path --tmux-path --tmux-conf --log-file --log-level --log-format --help'
 
 	# Commands
 	if [ "${#COMP_WORDS[@]}" -eq 2 ]; then
@@ -19,20 +19,45 @@ _jig() {
 	# Projects
 	if [ "${#COMP_WORDS[@]}" -eq 3 ]; then
 		case ${prev} in
-		sta | star | start | sto | stop | l | ls | list | e | ed | edit | n | ne | new)
+		sta | star | start | sto | stop | l | ls | list | e | ed | edit | n | ne | new | exp | export)
 			COMPREPLY=($(compgen -W "$(jig list)" -- "${cur}"))
 			;;
 		p | pr | print | sw | swi | switch)
 			COMPREPLY=($(compgen -W "$(tmux ls -F '#S')" -- "${cur}"))
 			;;
+		imp | import)
+			COMPREPLY=($(compgen -W "--from" -- "${cur}"))
+			;;
 		esac
 		return
 	fi
 
 	# Flags
 	case $prev in
-	-w | --windows) return ;;
-	start | stop) opts="$opts --windows" ;;
+	-w | --windows | -j | --jobs | -L | --socket-name) return ;;
+	-S | --socket-path | --tmux-path | --tmux-conf | --log-file)
+		COMPREPLY=($(compgen -f -- "${cur}"))
+		return
+		;;
+	--log-level)
+		COMPREPLY=($(compgen -W "debug info warn error" -- "${cur}"))
+		return
+		;;
+	--log-format)
+		COMPREPLY=($(compgen -W "text json" -- "${cur}"))
+		return
+		;;
+	--from)
+		COMPREPLY=($(compgen -W "tmuxinator tmuxp smug" -- "${cur}"))
+		return
+		;;
+	--to)
+		COMPREPLY=($(compgen -W "sh tmuxp tmuxinator" -- "${cur}"))
+		return
+		;;
+	exp | export) opts="$opts --to" ;;
+	start) opts="$opts --windows --jobs --timings --profile --no-control --keep-on-error" ;;
+	stop) opts="$opts --windows" ;;
 	esac
 
 	# Suggest options that were not specified already
@@ -42,7 +67,19 @@ _jig() {
 		-d | --detach) opts="${opts/--detach/}" ;;
 		-w | --windows) opts="${opts/--windows/}" ;;
 		-i | --inside) opts="${opts/--inside/}" ;;
+		-j | --jobs) opts="${opts/--jobs/}" ;;
+		-L | --socket-name) opts="${opts/--socket-name/}" ;;
+		-S | --socket-path) opts="${opts/--socket-path/}" ;;
+		--tmux-path) opts="${opts/--tmux-path/}" ;;
+		--tmux-conf) opts="${opts/--tmux-conf/}" ;;
+		--timings) opts="${opts/--timings/}" ;;
+		--profile) opts="${opts/--profile/}" ;;
+		--no-control) opts="${opts/--no-control/}" ;;
+		--keep-on-error) opts="${opts/--keep-on-error/}" ;;
 		--debug) opts="${opts/--debug/}" ;;
+		--log-file) opts="${opts/--log-file/}" ;;
+		--log-level) opts="${opts/--log-level/}" ;;
+		--log-format) opts="${opts/--log-format/}" ;;
 		--help) opts="${opts/--help/}" ;;
 		esac
 	done
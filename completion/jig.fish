@@ -8,8 +8,23 @@ function __fish_jig_complete_sessions
 	tmux ls -F '#S'
 end
 
-set -l jig_commands start stop print list edit new switch version
+set -l jig_commands start stop print list edit new switch import export version
 
 complete -f -c jig -n "not __fish_seen_subcommand_from $jig_commands" -a "$jig_commands"
-complete -f -c jig -n "__fish_seen_subcommand_from start stop list edit new; and not __fish_seen_subcommand_from (__fish_jig_complete_projects)" -a "(__fish_jig_complete_projects)"
+complete -f -c jig -n "__fish_seen_subcommand_from start stop list edit new export; and not __fish_seen_subcommand_from (__fish_jig_complete_projects)" -a "(__fish_jig_complete_projects)"
 complete -f -c jig -n "__fish_seen_subcommand_from print switch; and not __fish_seen_subcommand_from (__fish_jig_complete_sessions)" -a "(__fish_jig_complete_sessions)"
+complete -x -c jig -n "__fish_seen_subcommand_from import" -l from -a "tmuxinator tmuxp smug"
+complete -F -c jig -n "__fish_seen_subcommand_from import"
+complete -x -c jig -n "__fish_seen_subcommand_from export" -l to -a "sh tmuxp tmuxinator"
+complete -x -c jig -n "__fish_seen_subcommand_from start" -s j -l jobs -d "Number of nested sessions to start concurrently"
+complete -f -c jig -n "__fish_seen_subcommand_from start" -l timings -d "Report how long it took to start each session"
+complete -f -c jig -n "__fish_seen_subcommand_from start" -l profile -d "Report where the time of a start went"
+complete -f -c jig -n "__fish_seen_subcommand_from start" -l no-control -d "Run a tmux process per command"
+complete -f -c jig -n "__fish_seen_subcommand_from start" -l keep-on-error -d "Keep the sessions and windows created by a failed start"
+complete -x -c jig -s L -l socket-name -d "Name of the tmux server socket"
+complete -r -F -c jig -s S -l socket-path -d "Path to the tmux server socket"
+complete -r -F -c jig -l tmux-path -d "Name or path of the tmux command"
+complete -r -F -c jig -l tmux-conf -d "Path to the config file of a new tmux server"
+complete -r -F -c jig -l log-file -d "Append the log to a file"
+complete -x -c jig -l log-level -a "debug info warn error" -d "Level of the log"
+complete -x -c jig -l log-format -a "text json" -d "Format of the log"
@@ -7,6 +7,7 @@ require (
 	github.com/charmbracelet/lipgloss v0.10.0
 	github.com/stretchr/testify v1.9.0
 	github.com/xlab/treeprint v1.2.0
+	golang.org/x/sys v0.20.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -20,5 +21,4 @@ require (
 	github.com/muesli/termenv v0.15.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/sys v0.20.0 // indirect
 )
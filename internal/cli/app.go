@@ -25,6 +25,8 @@ $ jig start foo:win1
 $ jig start foo -w win1
 $ jig start foo:win1,win2
 $ jig stop foo
+$ jig import --from tmuxinator ~/.config/tmuxinator/foo.yml
+$ jig export --to sh foo > foo.sh
 `
 )
 
@@ -40,6 +42,8 @@ type CLI struct {
 	Edit    EditCmd    `cmd:"" help:"Edit the a tmux session configuration." aliases:"ed,e"`
 	New     NewCmd     `cmd:"" help:"Create a new tmux session." aliases:"ne,n"`
 	Switch  SwitchCmd  `cmd:"" help:"Switch to existing tmux session." aliases:"swi,sw"`
+	Import  ImportCmd  `cmd:"" help:"Convert a tmuxinator, tmuxp or smug config." aliases:"imp"`
+	Export  ExportCmd  `cmd:"" help:"Export a project as a shell script, tmuxp or tmuxinator config." aliases:"exp"`
 	Version VersionCmd `cmd:"" help:"Display version information." aliases:"ver,v"`
 }
 
@@ -94,6 +94,13 @@ func TestCommandArgs(t *testing.T) {
 			nil,
 			nil,
 		},
+		{
+			[]string{"start", "-d", "-L", "work", "test"},
+			[]string{},
+			client.Options{Detach: true, SocketName: "work"},
+			nil,
+			nil,
+		},
 		{
 			[]string{"start", "project", "a=b", "x=y"},
 			[]string{},
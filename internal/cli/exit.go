@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// Exit codes of jig, so that scripts can tell why it failed.
+const (
+	ExitError       = 1   // Invalid usage, or another error.
+	ExitConfig      = 2   // The config is missing, or invalid, e.g. a path is missing or tmux rejected a layout.
+	ExitNotFound    = 3   // A tmux session, window or pane doesn't exist.
+	ExitExists      = 4   // The tmux session already exists.
+	ExitTmux        = 5   // tmux isn't running, is too old, or can't fit a pane.
+	ExitTimeout     = 6   // A before or after command, or a wait timed out.
+	ExitInterrupted = 130 // jig was interrupted.
+)
+
+// exitCodes are the exit codes of errors, in the order they're checked.
+var exitCodes = []struct {
+	code int
+	errs []error
+}{
+	{ExitInterrupted, []error{context.Canceled}},
+	{ExitTimeout, []error{client.ErrHookTimeout, client.ErrWaitTimeout, context.DeadlineExceeded}},
+	{ExitConfig, []error{
+		ErrConfigNotFound{},
+		client.ErrConfigNotFound,
+		client.ErrInvalidConfig,
+		client.ErrNoSessionName,
+		client.ErrInvalidRestart,
+		client.ErrInvalidWait,
+		client.ErrDependencyCycle,
+		client.ErrUnknownDependency,
+		client.ErrMissingPath,
+		client.ErrBindingConflict,
+		tmux.ErrInvalidLayout,
+		tmux.ErrInvalidOption,
+		tmux.ErrInvalidSplitType,
+		tmux.ErrInvalidSize,
+	}},
+	{ExitNotFound, []error{tmux.ErrSessionNotFound, tmux.ErrWindowNotFound, tmux.ErrPaneNotFound}},
+	{ExitExists, []error{tmux.ErrDuplicateSession}},
+	{ExitTmux, []error{
+		tmux.ErrNoServer,
+		tmux.ErrUnsupportedVersion,
+		tmux.ErrUnknownCommand,
+		tmux.ErrNoSpace,
+	}},
+}
+
+// ExitCode returns the exit code of jig for an error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	for _, c := range exitCodes {
+		for _, e := range c.errs {
+			if errors.Is(err, e) {
+				return c.code
+			}
+		}
+	}
+	return ExitError
+}
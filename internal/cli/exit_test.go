@@ -0,0 +1,43 @@
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/internal/cli"
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+func TestExitCode(t *testing.T) {
+	tmuxErr := func(reason error) error {
+		return &tmux.Error{Reason: reason, Err: &shell.ShellError{Command: "tmux", Err: errors.New("exit status 1")}}
+	}
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("unknown"), cli.ExitError},
+		{client.ErrNotInsideSession, cli.ExitError},
+		{cli.ErrConfigNotFound{Project: "foo", Path: "/foo.yml"}, cli.ExitConfig},
+		{fmt.Errorf("%w: open: no such file", client.ErrConfigNotFound), cli.ExitConfig},
+		{fmt.Errorf("session %q: window %q: select layout: %w", "ses", "win", tmuxErr(tmux.ErrInvalidLayout)), cli.ExitConfig},
+		{fmt.Errorf("session %q: %w: /src (window %q)", "ses", client.ErrMissingPath, "win"), cli.ExitConfig},
+		{fmt.Errorf("session %q: %w: root M-t", "ses", client.ErrBindingConflict), cli.ExitConfig},
+		{tmuxErr(tmux.ErrSessionNotFound), cli.ExitNotFound},
+		{tmuxErr(tmux.ErrDuplicateSession), cli.ExitExists},
+		{tmuxErr(tmux.ErrNoServer), cli.ExitTmux},
+		{fmt.Errorf("%w: requires tmux ≥ 3.2", tmux.ErrUnsupportedVersion), cli.ExitTmux},
+		{fmt.Errorf("%w file ready after 30s", client.ErrWaitTimeout), cli.ExitTimeout},
+		{errors.Join(context.Canceled, tmuxErr(tmux.ErrSessionNotFound)), cli.ExitInterrupted},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.code, cli.ExitCode(tc.err), tc.err)
+	}
+}
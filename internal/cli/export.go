@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/convert"
+)
+
+type ExportCmd struct {
+	To        string            `help:"Format to export to (${enum})." enum:"sh,tmuxp,tmuxinator" required:""`
+	Project   string            `help:"Project name to export." arg:"" optional:""`
+	Variables map[string]string `help:"Variable to interpolate in session config." arg:"" optional:""`
+	Windows   []string          `help:"List of windows to export, only for sh." short:"w" sep:","`
+}
+
+// Run executes the export command.
+func (c *ExportCmd) Run(jig client.Jig) error {
+	configPath, err := FindProjectFile(c.Project, jig.Options.File)
+	if err != nil {
+		return err
+	}
+	config, err := client.LoadConfig(configPath, c.Variables)
+	if err != nil {
+		return err
+	}
+
+	if c.To == "sh" {
+		script, err := jig.Script(config, c.Windows)
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	}
+
+	raw, dropped, err := convert.Export(convert.Format(c.To), config)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(raw))
+
+	if len(dropped) > 0 {
+		fmt.Fprintf(os.Stderr, "Dropped unsupported keys: %s\n", strings.Join(dropped, ", "))
+	}
+	return nil
+}
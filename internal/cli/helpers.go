@@ -18,6 +18,12 @@ func (e ErrConfigNotFound) Error() string {
 	return fmt.Sprintf("config not found for project %s at %q", e.Project, e.Path)
 }
 
+// Is matches any ErrConfigNotFound, whatever its project.
+func (e ErrConfigNotFound) Is(target error) bool {
+	_, ok := target.(ErrConfigNotFound)
+	return ok
+}
+
 // FindProjectFile parses the cli arguments and returns a runtime configuration.
 func FindProjectFile(name, file string) (string, error) {
 	var err error
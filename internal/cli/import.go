@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/convert"
+)
+
+type ImportCmd struct {
+	From string `help:"Format of the config file (${enum})." enum:"tmuxinator,tmuxp,smug" required:""`
+	File string `arg:"" help:"Path to a config file to convert." type:"existingfile"`
+}
+
+// Run executes the import command.
+func (c *ImportCmd) Run(jig client.Jig) error {
+	data, err := os.ReadFile(c.File)
+	if err != nil {
+		return err
+	}
+	config, dropped, err := convert.Import(convert.Format(c.From), data)
+	if err != nil {
+		return err
+	}
+
+	raw, err := encodeYAML(config, printIdent)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(raw))
+
+	if len(dropped) > 0 {
+		fmt.Fprintf(os.Stderr, "Dropped unsupported keys: %s\n", strings.Join(dropped, ", "))
+	}
+	return nil
+}
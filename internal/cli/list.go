@@ -58,14 +58,24 @@ func displayConfigTree(project client.Config) treeprint.Tree {
 	for _, win := range project.Windows {
 		winBranch := treeprint.New()
 		winBranch.SetValue(makeTreeWindowEntry(win))
-		for _, pane := range win.Panes {
-			winBranch.AddNode(makeTreePaneEntry(pane))
-		}
+		addTreePanes(winBranch, win.Panes)
 		tree.AddNode(winBranch)
 	}
 	return tree
 }
 
+// addTreePanes recursively adds panes and their nested panes to a tree.
+func addTreePanes(tree treeprint.Tree, panes []client.Pane) {
+	for _, pane := range panes {
+		if len(pane.Panes) == 0 {
+			tree.AddNode(makeTreePaneEntry(pane))
+			continue
+		}
+		branch := tree.AddBranch(makeTreePaneEntry(pane))
+		addTreePanes(branch, pane.Panes)
+	}
+}
+
 // makeTreeWindowEntry builds a tree entry for a window.
 func makeTreeWindowEntry(win client.Window) string {
 	if len(win.Cmd) > 0 {
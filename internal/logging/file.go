@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File is a log file which is appended to, and rotated once it reaches its
+// maximum size, keeping a single previous file with a ".1" suffix. Runs of
+// jig share the file, as each record is written at once in append mode.
+type File struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+}
+
+// OpenFile opens a log file for appending, creating it and its directory if
+// needed.
+func OpenFile(path string, maxSize int64) (*File, error) {
+	f := &File{path: path, maxSize: maxSize}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends a record, after rotating the file if the record would
+// exceed its maximum size.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reopen(); err != nil {
+		return 0, err
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// reopen opens the file again if another run rotated it, so that records
+// aren't appended to the previous file, and updates its size with the
+// records of other runs.
+func (f *File) reopen() error {
+	if info, ok := f.current(); ok {
+		f.size = info.Size()
+		return nil
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// current returns the file at the path, and true if it's the open file.
+func (f *File) current() (os.FileInfo, bool) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, false
+	}
+	open, err := f.file.Stat()
+	return info, err == nil && os.SameFile(info, open)
+}
+
+// rotate renames the file to the previous file, and opens a new one. If
+// another run rotated it already, its new file is opened instead.
+func (f *File) rotate() error {
+	_, current := f.current()
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if current {
+		if err := os.Rename(f.path, f.path+".1"); err != nil {
+			return err
+		}
+	}
+	return f.open()
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,86 @@
+// Package logging sets up the log of jig, which concurrent runs append to.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultMaxSize is the size of a log file from which it's rotated.
+	DefaultMaxSize = 10 << 20
+
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options select where jig logs, and what.
+type Options struct {
+	// Path is the log file. Nothing is logged without one.
+	Path string
+
+	// Level is the minimal level of records, e.g. "debug" or "warn".
+	// Defaults to "info".
+	Level string
+
+	// Format is either "text", the default, or "json".
+	Format string
+
+	// MaxSize is the size in bytes of a log file from which it's rotated.
+	// Defaults to DefaultMaxSize.
+	MaxSize int64
+}
+
+// DefaultPath returns the default log file, in the user's cache directory.
+func DefaultPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".cache", "jig.log")
+}
+
+// New returns a logger which appends to the log file, and a function to
+// close it. Without a log file the logger discards all records.
+func New(opts Options) (*slog.Logger, func() error, error) {
+	if opts.Path == "" {
+		return Discard(), func() error { return nil }, nil
+	}
+	level := slog.LevelInfo
+	if opts.Level != "" {
+		if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+			return nil, nil, fmt.Errorf("invalid log level %q", opts.Level)
+		}
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+
+	var newHandler func(w io.Writer, opts *slog.HandlerOptions) slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewTextHandler(w, opts)
+		}
+	case FormatJSON:
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewJSONHandler(w, opts)
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid log format %q, expected text or json", opts.Format)
+	}
+
+	file, err := OpenFile(opts.Path, opts.MaxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	handler := newHandler(file, &slog.HandlerOptions{Level: level})
+	return slog.New(handler).With("pid", os.Getpid()), file.Close, nil
+}
+
+// Discard returns a logger which discards all records.
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{
+		Level: slog.Level(127),
+	}))
+}
@@ -0,0 +1,102 @@
+package logging_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/internal/logging"
+)
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "jig.log")
+	logger, closeLog, err := logging.New(logging.Options{Path: path, Format: "json", Level: "warn"})
+	require.NoError(t, err)
+	logger.Info("skipped")
+	logger.Warn("failed", "session", "work")
+	require.NoError(t, closeLog())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(data, &record))
+	assert.Equal(t, "failed", record["msg"])
+	assert.Equal(t, "work", record["session"])
+	assert.Equal(t, float64(os.Getpid()), record["pid"])
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, _, err := logging.New(logging.Options{Path: "jig.log", Level: "loud"})
+	assert.EqualError(t, err, `invalid log level "loud"`)
+	_, _, err = logging.New(logging.Options{Path: "jig.log", Format: "xml"})
+	assert.EqualError(t, err, `invalid log format "xml", expected text or json`)
+}
+
+func TestNewDiscard(t *testing.T) {
+	logger, closeLog, err := logging.New(logging.Options{})
+	require.NoError(t, err)
+	logger.Error("discarded")
+	assert.NoError(t, closeLog())
+}
+
+func TestFileRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jig.log")
+	f, err := logging.OpenFile(path, 10)
+	require.NoError(t, err)
+	for _, record := range []string{"one\n", "two\n", "three\n"} {
+		_, err := f.Write([]byte(record))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(data))
+	data, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+
+	// A file opened again is appended to.
+	f, err = logging.OpenFile(path, 10)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("four\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	data, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "three"))
+}
+
+func TestFileRotateConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jig.log")
+	write := func(f *logging.File, record string) {
+		_, err := f.Write([]byte(record))
+		require.NoError(t, err)
+	}
+	first, err := logging.OpenFile(path, 12)
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := logging.OpenFile(path, 12)
+	require.NoError(t, err)
+	defer second.Close()
+
+	// Records of other runs count towards the size, and a run which didn't
+	// rotate the file writes to the new one, without rotating it over the
+	// previous file early.
+	write(first, "one\n")
+	write(second, "two\n")
+	write(first, "three\n")
+	write(second, "four\n")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "three\nfour\n", string(data))
+	data, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// defaultKeyTable is the table of bindings without an explicit table.
+const defaultKeyTable = "prefix"
+
+// setHooks sets the session's hooks, in a stable order.
+func (j Jig) setHooks(session Config) error {
+	target := tmux.Target{Session: session.Session}
+	for _, hook := range sortedKeys(session.Hooks) {
+		if err := j.Tmux.SetHook(target, tmux.OptionScopeSession, hook, session.Hooks[hook]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindKeys installs the session's key bindings. tmux has one prefix table
+// for all sessions, so keys are bound in their table to a command which runs
+// the session's binding only while the client is in this session, and falls
+// back to the original binding otherwise. Bindings are read as printed by
+// list-keys, and split into arguments to be bound again. The original binding is stashed in
+// the session's own key table to be restored on stop. A key bound by another
+// session is refused, as stopping either session would restore the wrong
+// binding.
+func (j Jig) bindKeys(session Config) error {
+	stash := sessionKeyTable(session.Session)
+	cond := sessionCondition(session.Session)
+	for _, binding := range sortedKeys(session.Bindings) {
+		table, key := parseBinding(binding)
+		dispatch := []string{"if-shell", "-F", cond, session.Bindings[binding]}
+
+		orig := j.Tmux.KeyBinding(table, key)
+		switch {
+		case strings.Contains(orig, cond):
+			// Session is restarted, keep the original binding as stashed.
+			orig = j.Tmux.KeyBinding(stash, key)
+		case strings.Contains(orig, sessionConditionPrefix):
+			return fmt.Errorf("%w: %s", ErrBindingConflict, binding)
+		case orig != "":
+			if err := j.Tmux.BindKey(stash, key, tmux.SplitCommand(orig)...); err != nil {
+				return err
+			}
+		}
+		if orig != "" {
+			dispatch = append(dispatch, tmux.JoinCommand(tmux.SplitCommand(orig)))
+		}
+		if err := j.Tmux.BindKey(table, key, dispatch...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unbindKeys removes the session's key bindings and restores the original
+// bindings. Keys bound to something else since the session started are left
+// as they are.
+func (j Jig) unbindKeys(session Config) error {
+	stash := sessionKeyTable(session.Session)
+	cond := sessionCondition(session.Session)
+	for _, binding := range sortedKeys(session.Bindings) {
+		table, key := parseBinding(binding)
+		orig := j.Tmux.KeyBinding(stash, key)
+		if orig != "" {
+			if err := j.Tmux.UnbindKey(stash, key); err != nil {
+				return err
+			}
+		}
+		if !strings.Contains(j.Tmux.KeyBinding(table, key), cond) {
+			continue
+		}
+		if orig == "" {
+			if err := j.Tmux.UnbindKey(table, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := j.Tmux.BindKey(table, key, tmux.SplitCommand(orig)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseBinding splits a binding into its key table and key, e.g. "T" is
+// bound in the prefix table, and "root M-t" in the root table.
+func parseBinding(binding string) (string, string) {
+	fields := strings.Fields(binding)
+	if len(fields) == 2 {
+		return fields[0], fields[1]
+	}
+	return defaultKeyTable, binding
+}
+
+// sessionConditionPrefix starts the condition of every session's bindings.
+const sessionConditionPrefix = "#{==:#{session_name},"
+
+// sessionCondition returns the format which is true while the client is in
+// a session. The name is escaped, as commas and braces end the comparison.
+func sessionCondition(session string) string {
+	escaped := strings.NewReplacer("#", "##", ",", "#,", "}", "#}").Replace(session)
+	return sessionConditionPrefix + escaped + "}"
+}
+
+// sessionKeyTable returns the name of the key table for a session.
+func sessionKeyTable(session string) string {
+	return "jig-" + session
+}
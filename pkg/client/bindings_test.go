@@ -0,0 +1,131 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+func TestHooksAndBindings(t *testing.T) {
+	config := client.Config{
+		Session: "ses",
+		Path:    "/tmp",
+		Hooks:   map[string]string{"client-attached": "display-message hi"},
+		Bindings: map[string]string{
+			"T":        "run-shell 'make test'",
+			"root M-t": "display-message root",
+		},
+	}
+
+	t.Run("start session", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{
+			"ses",
+			"$1",
+			"bind-key -T prefix T new-window",
+			"",
+		}}
+		jig := client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Options: client.Options{Detach: true},
+		}
+		assert.NoError(t, jig.Start(config, []string{}))
+		assert.Equal(t, []string{
+			"tmux has-session -t ses:",
+			"tmux new-session -Pd -F #{session_id} -s ses -c /tmp",
+			"tmux set-hook -t ses: client-attached display-message hi",
+			"tmux list-keys -T prefix T",
+			"tmux bind-key -T jig-ses T new-window",
+			"tmux bind-key -T prefix T if-shell -F #{==:#{session_name},ses} run-shell 'make test' new-window",
+			"tmux list-keys -T root M-t",
+			"tmux bind-key -T root M-t if-shell -F #{==:#{session_name},ses} display-message root",
+		}, commander.Commands)
+	})
+
+	t.Run("stop session", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{
+			"bind-key -T jig-ses T new-window",
+			`bind-key -T prefix T if-shell -F "#{==:#{session_name},ses}" "run-shell 'make test'" new-window`,
+			"",
+			"bind-key -T root M-t display-message other",
+			"",
+		}}
+		jig := client.Jig{Tmux: tmux.TmuxClient{Bin: "tmux", Cmd: commander}}
+		assert.NoError(t, jig.Stop(config, []string{}))
+		assert.Equal(t, []string{
+			"tmux list-keys -T jig-ses T",
+			"tmux unbind-key -T jig-ses T",
+			"tmux list-keys -T prefix T",
+			"tmux bind-key -T prefix T new-window",
+			"tmux list-keys -T jig-ses M-t",
+			"tmux list-keys -T root M-t",
+			"tmux kill-session -t ses:",
+		}, commander.Commands)
+	})
+
+	t.Run("key bound by another session", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{
+			"ses",
+			"$1",
+			"",
+			`bind-key -T root M-t if-shell -F "#{==:#{session_name},other}" "display-message other"`,
+		}}
+		jig := client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Options: client.Options{Detach: true},
+		}
+		err := jig.Start(config, []string{})
+		assert.ErrorIs(t, err, client.ErrBindingConflict)
+		assert.ErrorContains(t, err, "root M-t")
+	})
+
+	t.Run("command list binding", func(t *testing.T) {
+		list := client.Config{
+			Session:  "ses",
+			Path:     "/tmp",
+			Bindings: map[string]string{"r": "display-message mine"},
+		}
+		orig := `bind-key -T prefix r source-file /root/.tmux.conf \; display-message reloaded`
+		commander := &MockCommander{[]string{}, []string{"ses", "$1", orig, ""}}
+		jig := client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Options: client.Options{Detach: true},
+		}
+		assert.NoError(t, jig.Start(list, []string{}))
+		assert.Equal(t, []string{
+			"tmux list-keys -T prefix r",
+			`tmux bind-key -T jig-ses r source-file /root/.tmux.conf \; display-message reloaded`,
+			"tmux bind-key -T prefix r if-shell -F #{==:#{session_name},ses} display-message mine " +
+				"source-file /root/.tmux.conf ; display-message reloaded",
+		}, commander.Commands[2:])
+
+		commander = &MockCommander{[]string{}, []string{
+			`bind-key -T jig-ses r source-file /root/.tmux.conf \; display-message reloaded`,
+			`bind-key -T prefix r if-shell -F "#{==:#{session_name},ses}" "display-message mine" ` +
+				`"source-file /root/.tmux.conf ; display-message reloaded"`,
+			"",
+		}}
+		jig.Tmux = tmux.TmuxClient{Bin: "tmux", Cmd: commander}
+		assert.NoError(t, jig.Stop(list, []string{}))
+		assert.Contains(t, commander.Commands,
+			`tmux bind-key -T prefix r source-file /root/.tmux.conf \; display-message reloaded`)
+	})
+
+	t.Run("escaped session name", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{"a,b}", "$1", ""}}
+		jig := client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Options: client.Options{Detach: true},
+		}
+		escaped := client.Config{
+			Session:  "a,b}",
+			Path:     "/tmp",
+			Bindings: map[string]string{"T": "run-shell 'make test'"},
+		}
+		assert.NoError(t, jig.Start(escaped, []string{}))
+		assert.Contains(t, commander.Commands,
+			"tmux bind-key -T prefix T if-shell -F #{==:#{session_name},a#,b#}} run-shell 'make test'")
+	})
+}
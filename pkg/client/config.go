@@ -2,14 +2,17 @@ package client
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
 	"github.com/rafi/jig/pkg/yaml/processor"
 )
 
@@ -28,35 +31,112 @@ type Config struct {
 	Windows         []Window          `yaml:"windows"`
 	CommandDelay    int               `yaml:"command_delay,omitempty"`
 	SuppressHistory bool              `yaml:"suppress_history,omitempty"`
+	Options         map[string]string `yaml:"options,omitempty"`
+	WindowOptions   map[string]string `yaml:"window_options,omitempty"`
+	PaneTitles      string            `yaml:"pane_titles,omitempty"`
+	Hooks           map[string]string `yaml:"hooks,omitempty"`
+	Bindings        map[string]string `yaml:"bindings,omitempty"`
 	Sessions        []Config          `yaml:"sessions,omitempty"`
 
+	// BeforeTimeout and AfterTimeout stop each of the before and after
+	// commands once it runs longer, e.g. "2m", rather than the whole list.
+	// By default they run until they exit.
+	BeforeTimeout time.Duration `yaml:"before_timeout,omitempty"`
+	AfterTimeout  time.Duration `yaml:"after_timeout,omitempty"`
+
+	// DependsOn are names of sibling sessions to start before this one.
+	DependsOn []string `yaml:"depends_on,omitempty"`
+
+	// SocketName and SocketPath select the tmux server of the session, as
+	// with tmux's -L and -S flags. Nested sessions default to their parent's.
+	// A relative socket path is relative to the config file.
+	SocketName string `yaml:"socket_name,omitempty"`
+	SocketPath string `yaml:"socket_path,omitempty"`
+
+	// TmuxConf is the config file of a server started by jig, and
+	// TmuxCommand replaces tmux, e.g. with tmate or a wrapper script.
+	// Relative paths are relative to the config file.
+	TmuxConf    string `yaml:"tmux_conf,omitempty"`
+	TmuxCommand string `yaml:"tmux_command,omitempty"`
+
 	ConfigPath string `yaml:"config_path,omitempty"`
+
+	PathSource `yaml:",inline"`
+}
+
+// PathSource makes a missing working directory of a session, window or pane
+// before the session starts, instead of failing to start it.
+type PathSource struct {
+	// CreatePath creates the directory and its parents.
+	CreatePath bool `yaml:"create_path,omitempty"`
+
+	// Clone clones a git repository into the directory, from a URL, or a
+	// local mirror or path relative to the config file. It takes precedence
+	// over CreatePath.
+	Clone string `yaml:"clone,omitempty"`
 }
 
 func (c Config) GetSessionPath() (string, error) {
 	// Resolve session start directory.
 	// If session path is empty, use config path.
 	// If session path is "." or "./", use current directory.
+	// Otherwise, relative paths are relative to the config file.
 	switch c.Path {
 	case "":
 		return filepath.Dir(c.ConfigPath), nil
 	case ".", "./":
 		return os.Getwd()
 	default:
-		return shell.ExpandPath(c.Path), nil
+		return shell.ResolvePath(c.Path, filepath.Dir(c.ConfigPath)), nil
 	}
 }
 
+// UserEnv returns the environment variables defined by the user, without the
+// ones jig sets for every session.
+func (c Config) UserEnv() map[string]string {
+	env := make(map[string]string, len(c.Env))
+	for key, value := range c.Env {
+		if key != envSessionVarName && key != envSessionConfigPathVarName {
+			env[key] = value
+		}
+	}
+	return env
+}
+
 type Window struct {
 	Name     string   `yaml:"name"`
 	Before   []string `yaml:"before,omitempty"`
 	Panes    []Pane   `yaml:"panes,omitempty"`
+	Split    string   `yaml:"split,omitempty"`
 	Layout   string   `yaml:"layout"`
 	Focus    bool     `yaml:"focus,omitempty"`
 	Manual   bool     `yaml:"manual,omitempty"`
 	Path     string   `yaml:"path,omitempty"`
 	Commands []string `yaml:"commands,omitempty"`
 	Cmd      string   `yaml:"cmd,omitempty"`
+
+	// Title is the title of the window's first pane.
+	Title string `yaml:"title,omitempty"`
+
+	// PaneTitles shows pane titles in pane borders, "top" or "bottom".
+	PaneTitles string `yaml:"pane_titles,omitempty"`
+
+	// Options override the session's window options.
+	Options map[string]string `yaml:"window_options,omitempty"`
+
+	// DependsOn are names of windows in the session to create before this one.
+	DependsOn []string `yaml:"depends_on,omitempty"`
+
+	Process    `yaml:",inline"`
+	PathSource `yaml:",inline"`
+}
+
+// Validate checks the window's split type of its panes, and its process.
+func (w Window) Validate() error {
+	if err := tmux.ValidateSplit(w.Split); err != nil {
+		return err
+	}
+	return w.Process.Validate()
 }
 
 func (w Window) GetCommands() []string {
@@ -70,12 +150,34 @@ func (w Window) GetCommands() []string {
 	return cmds
 }
 
+// Pane is created by splitting the previous pane in the list, or its parent
+// if it's the first. Nested panes divide the pane's area after all of its
+// siblings were created, and default to the pane's split type.
 type Pane struct {
+	Title    string   `yaml:"title,omitempty"`
 	Type     string   `yaml:"type,omitempty"`
+	Size     string   `yaml:"size,omitempty"`
 	Path     string   `yaml:"path,omitempty"`
 	Focus    bool     `yaml:"focus,omitempty"`
 	Commands []string `yaml:"commands,omitempty"`
 	Cmd      string   `yaml:"cmd,omitempty"`
+	Split    string   `yaml:"split,omitempty"`
+	Panes    []Pane   `yaml:"panes,omitempty"`
+
+	Process    `yaml:",inline"`
+	PathSource `yaml:",inline"`
+}
+
+// Validate checks the pane's split type, the split type of its panes, and
+// its process.
+func (p Pane) Validate() error {
+	if err := tmux.ValidateSplit(p.Type); err != nil {
+		return err
+	}
+	if err := tmux.ValidateSplit(p.Split); err != nil {
+		return err
+	}
+	return p.Process.Validate()
 }
 
 func (p Pane) GetCommands() []string {
@@ -89,6 +191,55 @@ func (p Pane) GetCommands() []string {
 	return cmds
 }
 
+// Restart policies of a process.
+const (
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// Process is a command run in place of a pane's shell, instead of being typed
+// into it. A process that remains on exit, restarts or waits for a condition
+// is respawned after the pane is set up, so that it can't exit before.
+type Process struct {
+	Run          string `yaml:"run,omitempty"`
+	RemainOnExit bool   `yaml:"remain_on_exit,omitempty"`
+	Restart      string `yaml:"restart,omitempty"`
+
+	// Env are environment variables of the pane's shell and process, in
+	// addition to the session's. Panes inherit their window's and parent's.
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// WaitFor delays the process and typed commands until a condition is met.
+	WaitFor *WaitFor `yaml:"wait_for,omitempty"`
+}
+
+// Validate checks the process's restart policy and wait condition.
+func (p Process) Validate() error {
+	switch p.Restart {
+	case "", RestartOnFailure, RestartAlways:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidRestart, p.Restart)
+	}
+	if p.WaitFor != nil {
+		return p.WaitFor.Validate()
+	}
+	return nil
+}
+
+// Supervised returns true if the pane is kept or restarted on exit.
+func (p Process) Supervised() bool {
+	return p.RemainOnExit || p.Restart != ""
+}
+
+// Command returns the command to create the pane with, unless the process is
+// respawned later.
+func (p Process) Command() []string {
+	if p.Run == "" || p.Supervised() || p.WaitFor != nil {
+		return nil
+	}
+	return []string{p.Run}
+}
+
 // FindConfig finds the config filename in the specified directory.
 func FindConfig(dir, project string) (string, error) {
 	configPath := filepath.Join(dir, project)
@@ -139,13 +290,15 @@ func ListConfigs(dir string) ([]string, error) {
 // adds default environment variables and returns the final config.
 func LoadConfig(path string, vars map[string]string) (Config, error) {
 	f, err := os.ReadFile(path)
-	if err != nil {
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("%w: %w", ErrConfigNotFound, err)
+	} else if err != nil {
 		return Config{}, err
 	}
 
-	c, err := RenderConfig(string(f), vars)
+	c, err := renderConfig(string(f), vars, filepath.Dir(path))
 	if err != nil {
-		return c, err
+		return c, fmt.Errorf("%w %s: %w", ErrInvalidConfig, path, err)
 	}
 
 	// Resolve symlink path.
@@ -160,14 +313,36 @@ func LoadConfig(path string, vars map[string]string) (Config, error) {
 		path = realPath
 	}
 
-	c.ConfigPath = path
+	c.setConfigPath(path)
 	c.Env[envSessionVarName] = c.Session
 	c.Env[envSessionConfigPathVarName] = path
 	return c, err
 }
 
-// RenderConfig renders contents with supplied variables.
+// setConfigPath sets the config file of a session, which its relative paths
+// are resolved from, and of its nested sessions which weren't included from
+// another file.
+func (c *Config) setConfigPath(path string) {
+	c.ConfigPath = path
+	for i := range c.Sessions {
+		nested := &c.Sessions[i]
+		if nested.ConfigPath == "" {
+			nested.setConfigPath(path)
+		} else {
+			nested.setConfigPath(nested.ConfigPath)
+		}
+	}
+}
+
+// RenderConfig renders contents with supplied variables. Included files are
+// relative to the current directory.
 func RenderConfig(data string, vars map[string]string) (Config, error) {
+	return renderConfig(data, vars, "")
+}
+
+// renderConfig renders contents with supplied variables, and files included
+// relative to a directory.
+func renderConfig(data string, vars map[string]string, dir string) (Config, error) {
 	data = os.Expand(data, func(v string) string {
 		if val, ok := vars[v]; ok {
 			return val
@@ -184,7 +359,7 @@ func RenderConfig(data string, vars map[string]string) (Config, error) {
 		CommandDelay: defaultCommandDelay,
 	}
 
-	err := yaml.Unmarshal([]byte(data), &processor.IncludeProcessor{Out: &c})
+	err := yaml.Unmarshal([]byte(data), &processor.IncludeProcessor{Out: &c, Dir: dir})
 	if err != nil {
 		return Config{}, err
 	}
@@ -1,9 +1,12 @@
 package client_test
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/rafi/jig/pkg/client"
 )
@@ -51,3 +54,115 @@ windows:
 		t.Fatalf("expected %v, got %v", expected, config)
 	}
 }
+
+func TestRenderProcess(t *testing.T) {
+	yaml := `
+session: test
+windows:
+  - run: make serve
+    restart: on-failure
+    env:
+      DEBUG: "1"
+    panes:
+      - run: tail -f log
+        remain_on_exit: true
+      - run: top
+        restart: sometimes
+      - cmd: psql
+        wait_for:
+          tcp: localhost:5432
+          timeout: 10s`
+
+	config, err := client.RenderConfig(yaml, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	window := config.Windows[0]
+	expected := client.Process{
+		Run:     "make serve",
+		Restart: client.RestartOnFailure,
+		Env:     map[string]string{"DEBUG": "1"},
+	}
+	if !reflect.DeepEqual(window.Process, expected) {
+		t.Fatalf("expected %v, got %v", expected, window.Process)
+	}
+	if err := window.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := window.Command(); cmd != nil {
+		t.Fatalf("expected supervised process to be respawned, got %v", cmd)
+	}
+
+	if !window.Panes[0].RemainOnExit || window.Panes[0].Run != "tail -f log" {
+		t.Fatalf("unexpected pane process %v", window.Panes[0].Process)
+	}
+	if err := window.Panes[1].Validate(); !errors.Is(err, client.ErrInvalidRestart) {
+		t.Fatalf("expected invalid restart policy, got %v", err)
+	}
+	if cmd := window.Panes[1].Command(); cmd != nil {
+		t.Fatalf("expected supervised process to be respawned, got %v", cmd)
+	}
+
+	wait := client.WaitFor{TCP: "localhost:5432", Timeout: 10 * time.Second}
+	if w := window.Panes[2].WaitFor; w == nil || *w != wait {
+		t.Fatalf("expected %v, got %v", wait, w)
+	}
+}
+
+func TestLoadConfigPaths(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("JIG_TEST_DIR", root)
+	files := map[string]string{
+		"project/.jig.yml": `
+session: main
+path: src
+sessions:
+  - !include ../shared/.jig.yml
+  - session: inline
+    path: docs`,
+		// Included files aren't rendered, so their paths are expanded.
+		"shared/.jig.yml": `
+session: shared
+path: $JIG_TEST_DIR/code
+sessions:
+  - !include nested.yml`,
+		"shared/nested.yml": `
+session: nested`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := client.LoadConfig(filepath.Join(root, "project/.jig.yml"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Relative paths are relative to the file each session was loaded from.
+	sessions := []client.Config{config, config.Sessions[0], config.Sessions[0].Sessions[0], config.Sessions[1]}
+	expected := []string{
+		filepath.Join(root, "project/src"),
+		filepath.Join(root, "code"),
+		filepath.Join(root, "shared"),
+		filepath.Join(root, "project/docs"),
+	}
+	for i, session := range sessions {
+		path, err := session.GetSessionPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != expected[i] {
+			t.Errorf("session %q: expected path %q, got %q", session.Session, expected[i], path)
+		}
+	}
+	if path := config.Sessions[0].Sessions[0].ConfigPath; path != filepath.Join(root, "shared/nested.yml") {
+		t.Errorf("expected the config path of the included file, got %q", path)
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// dependencyOrder sorts items topologically, so that each item follows the
+// items it depends on, and otherwise keeps their given order. Items are
+// depended on by name, so a name which isn't unique can't be depended on.
+func dependencyOrder(names []string, dependsOn [][]string) ([]int, error) {
+	index := make(map[string]int, len(names))
+	duplicates := map[string]bool{}
+	for i, name := range names {
+		if _, ok := index[name]; ok {
+			duplicates[name] = true
+		}
+		index[name] = i
+	}
+
+	indegree := make([]int, len(names))
+	dependents := make([][]int, len(names))
+	for i, deps := range dependsOn {
+		for _, dep := range deps {
+			j, ok := index[dep]
+			if !ok || dep == "" {
+				return nil, fmt.Errorf("%w: %q depends on %q", ErrUnknownDependency, names[i], dep)
+			}
+			if duplicates[dep] {
+				return nil, fmt.Errorf("%w: %q depends on %q, which isn't unique",
+					ErrInvalidConfig, names[i], dep)
+			}
+			indegree[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	order := make([]int, 0, len(names))
+	done := make([]bool, len(names))
+	for len(order) < len(names) {
+		next := -1
+		for i := range names {
+			if !done[i] && indegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			cycle := []string{}
+			for i, name := range names {
+				if !done[i] {
+					cycle = append(cycle, fmt.Sprintf("%q", name))
+				}
+			}
+			return nil, fmt.Errorf("%w between %s", ErrDependencyCycle, strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, i := range dependents[next] {
+			indegree[i]--
+		}
+	}
+	return order, nil
+}
+
+// sessionOrder returns the order of sessions by their dependencies. Session
+// names must be unique, as tmux sessions are.
+func sessionOrder(sessions []Config) ([]int, error) {
+	names := make([]string, len(sessions))
+	deps := make([][]string, len(sessions))
+	for i, s := range sessions {
+		if slices.Contains(names[:i], s.Session) {
+			return nil, fmt.Errorf("%w: duplicate session %q", ErrInvalidConfig, s.Session)
+		}
+		names[i] = s.Session
+		deps[i] = s.DependsOn
+	}
+	return dependencyOrder(names, deps)
+}
+
+// windowOrder returns the windows sorted by their dependencies.
+func windowOrder(windows []Window) ([]Window, error) {
+	names := make([]string, len(windows))
+	deps := make([][]string, len(windows))
+	for i, w := range windows {
+		names[i] = w.Name
+		deps[i] = w.DependsOn
+	}
+	order, err := dependencyOrder(names, deps)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]Window, len(windows))
+	for i, k := range order {
+		sorted[i] = windows[k]
+	}
+	return sorted, nil
+}
@@ -0,0 +1,146 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+func TestDependencies(t *testing.T) {
+	newJig := func(commander *MockCommander) client.Jig {
+		return client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Cmd:     commander,
+			Options: client.Options{Detach: true},
+		}
+	}
+
+	config := client.Config{
+		Session: "ses",
+		Path:    "/tmp",
+		Sessions: []client.Config{
+			{Session: "web", Path: "/tmp", DependsOn: []string{"api"}},
+			{Session: "api", Path: "/tmp", DependsOn: []string{"db"}},
+			{Session: "db", Path: "/tmp"},
+		},
+		Windows: []client.Window{
+			{Name: "logs", DependsOn: []string{"code"}},
+			{Name: "code"},
+		},
+	}
+
+	t.Run("start in dependency order", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{"ses"}}
+		assert.NoError(t, newJig(commander).Start(config, []string{}))
+		assert.Equal(t, []string{
+			"tmux has-session -t db:",
+			"tmux new-session -Pd -F #{session_id} -s db -c /tmp",
+			"tmux has-session -t api:",
+			"tmux new-session -Pd -F #{session_id} -s api -c /tmp",
+			"tmux has-session -t web:",
+			"tmux new-session -Pd -F #{session_id} -s web -c /tmp",
+			"tmux has-session -t ses:",
+			"tmux new-session -Pd -F #{session_id} -s ses -n code -c /tmp",
+			"tmux new-window -Pd -t ses: -n logs -F #{window_id} -c /tmp",
+		}, commander.Commands)
+	})
+
+	t.Run("stop in reverse order", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{}}
+		assert.NoError(t, newJig(commander).Stop(config, []string{}))
+		assert.Equal(t, []string{
+			"tmux kill-session -t ses:",
+			"tmux kill-session -t web:",
+			"tmux kill-session -t api:",
+			"tmux kill-session -t db:",
+		}, commander.Commands)
+	})
+
+	t.Run("duplicate windows without dependents", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{"ses"}}
+		err := newJig(commander).Start(client.Config{
+			Session: "ses",
+			Path:    "/tmp",
+			Windows: []client.Window{{Name: "shell"}, {Name: "shell"}},
+		}, []string{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("start independent sessions", func(t *testing.T) {
+		commander := &MockCommander{[]string{}, []string{"ses"}}
+		err := newJig(commander).Start(client.Config{
+			Session: "ses",
+			Path:    "/tmp",
+			Sessions: []client.Config{
+				{Session: "one", Path: "/tmp"},
+				{Session: "two", Path: "/tmp"},
+			},
+		}, []string{})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			"tmux has-session -t one:",
+			"tmux new-session -Pd -F #{session_id} -s one -c /tmp",
+			"tmux has-session -t two:",
+			"tmux new-session -Pd -F #{session_id} -s two -c /tmp",
+		}, commander.Commands[:4])
+		assert.Equal(t, []string{
+			"tmux has-session -t ses:",
+			"tmux new-session -Pd -F #{session_id} -s ses -c /tmp",
+		}, commander.Commands[4:])
+	})
+
+	t.Run("invalid dependencies", func(t *testing.T) {
+		for _, tc := range []struct {
+			config client.Config
+			err    error
+		}{
+			{
+				client.Config{Session: "ses", Sessions: []client.Config{
+					{Session: "a", DependsOn: []string{"b"}},
+					{Session: "b", DependsOn: []string{"a"}},
+				}},
+				client.ErrDependencyCycle,
+			},
+			{
+				client.Config{Session: "ses", Sessions: []client.Config{
+					{Session: "a", DependsOn: []string{"ses"}},
+				}},
+				client.ErrUnknownDependency,
+			},
+			{
+				client.Config{Session: "ses", Windows: []client.Window{
+					{Name: "a", DependsOn: []string{"a"}},
+				}},
+				client.ErrDependencyCycle,
+			},
+			{
+				client.Config{Session: "ses", Sessions: []client.Config{
+					{Session: "a"},
+					{Session: "a"},
+				}},
+				client.ErrInvalidConfig,
+			},
+			{
+				client.Config{Session: "ses", Windows: []client.Window{
+					{Name: "a"},
+					{Name: "a"},
+					{Name: "b", DependsOn: []string{"a"}},
+				}},
+				client.ErrInvalidConfig,
+			},
+			{
+				client.Config{Session: "ses", Windows: []client.Window{
+					{},
+					{Name: "b", DependsOn: []string{""}},
+				}},
+				client.ErrUnknownDependency,
+			},
+		} {
+			commander := &MockCommander{[]string{}, []string{"ses"}}
+			assert.ErrorIs(t, newJig(commander).Start(tc.config, []string{}), tc.err)
+		}
+	})
+}
@@ -24,6 +24,8 @@ func (j Jig) GenerateSessionConfig(sessionName string) (Config, error) {
 	}
 
 	currentShell := filepath.Base(os.Getenv("SHELL"))
+	// tmux titles panes with the hostname by default.
+	hostname, _ := os.Hostname()
 
 	for _, w := range tmuxWindows {
 		target.Window = w.ID
@@ -31,6 +33,9 @@ func (j Jig) GenerateSessionConfig(sessionName string) (Config, error) {
 		if err != nil {
 			return config, err
 		}
+		if len(tmuxPanes) == 0 {
+			continue
+		}
 
 		window := Window{
 			Name:   w.Name,
@@ -38,29 +43,32 @@ func (j Jig) GenerateSessionConfig(sessionName string) (Config, error) {
 			Panes:  []Pane{},
 		}
 
-		// Set session's path to the first found window's path.
+		// The window's first pane is the window itself, and the session's
+		// path is the first window's.
 		if config.Path == "" {
-			config.Path = w.Path
+			config.Path = tmuxPanes[0].Path
 		}
-
-		// Skip window path if it is identical to session's path.
-		if w.Path != config.Path {
-			window.Path = w.Path
+		if tmuxPanes[0].Path != config.Path {
+			window.Path = tmuxPanes[0].Path
 		}
+		windowPath := tmuxPanes[0].Path
 
-		for _, tmuxPane := range tmuxPanes {
+		for i, tmuxPane := range tmuxPanes {
 			pane := Pane{Path: tmuxPane.Path}
 			if tmuxPane.Command != currentShell {
 				pane.Cmd = tmuxPane.Command
 			}
-			// Skip pane path if it is identical to window or session path.
-			if pane.Path == w.Path || (w.Path == "" && pane.Path == config.Path) {
-				pane.Path = ""
+			if tmuxPane.Title != hostname {
+				pane.Title = tmuxPane.Title
 			}
-			// Do not create a pane collection if there's only a single one.
-			if len(tmuxPanes) == 1 && pane.Path == "" {
+			if i == 0 {
 				window.Cmd = pane.Cmd
-				break
+				window.Title = pane.Title
+				continue
+			}
+			// Skip pane path if it is identical to window's path.
+			if pane.Path == windowPath {
+				pane.Path = ""
 			}
 			window.Panes = append(window.Panes, pane)
 		}
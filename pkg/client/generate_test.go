@@ -14,21 +14,20 @@ import (
 
 func TestPrintCurrentSession(t *testing.T) {
 	defaultShell := filepath.Base(os.Getenv("SHELL"))
+	hostname, _ := os.Hostname()
 
 	expectedConfig := client.Config{
 		Session: "foobar",
-		Path:    "/root",
+		Path:    "/opt",
 		Windows: []client.Window{
 			{
 				Name:   "win1",
 				Layout: "layout",
 				Panes: []client.Pane{
 					{
-						Path: "/opt",
-					},
-					{
-						Path: "/tmp",
-						Cmd:  "nvim",
+						Title: "editor",
+						Path:  "/tmp",
+						Cmd:   "nvim",
 					},
 				},
 			},
@@ -41,8 +40,8 @@ func TestPrintCurrentSession(t *testing.T) {
 			"foobar",
 			strings.Join([]string{"id1", "win1", "layout", "/root"}, tmux.ColumnSep),
 			strings.Join([]string{
-				strings.Join([]string{"/opt", defaultShell}, tmux.ColumnSep),
-				strings.Join([]string{"/tmp", "nvim"}, tmux.ColumnSep),
+				strings.Join([]string{"/opt", defaultShell, hostname}, tmux.ColumnSep),
+				strings.Join([]string{"/tmp", "nvim", "editor"}, tmux.ColumnSep),
 			}, "\n"),
 		},
 	}
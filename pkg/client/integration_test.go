@@ -0,0 +1,264 @@
+package client_test
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// tmuxServer is a tmux server on a private socket, started by the first
+// session, and killed when the test ends.
+type tmuxServer struct {
+	t      *testing.T
+	jig    client.Jig
+	socket string
+	root   string
+}
+
+// newTmuxServer returns a client of a private tmux server, and a root
+// directory for sessions. Tests against a real tmux server only run with
+// JIG_TEST_TMUX=1.
+func newTmuxServer(t *testing.T) *tmuxServer {
+	t.Helper()
+	if os.Getenv("JIG_TEST_TMUX") != "1" {
+		t.Skip("set JIG_TEST_TMUX=1 to run tests against tmux")
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not found")
+	}
+
+	// Socket paths are limited in length, so the directory is kept short.
+	dir, err := os.MkdirTemp("", "jig")
+	require.NoError(t, err)
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	for _, sub := range []string{"src", "docs", "logs"} {
+		require.NoError(t, os.Mkdir(filepath.Join(root, sub), 0o755))
+	}
+
+	s := &tmuxServer{t: t, socket: filepath.Join(dir, "tmux.sock"), root: root}
+	s.jig = client.New(client.Options{
+		Detach:     true,
+		SocketPath: s.socket,
+		TmuxConf:   "/dev/null",
+	}, shell.DefaultCommander{})
+	s.jig.InSession = false
+	s.jig.Out = &strings.Builder{}
+
+	t.Cleanup(func() {
+		_ = exec.Command("tmux", "-S", s.socket, "kill-server").Run()
+		_ = os.RemoveAll(dir)
+	})
+	return s
+}
+
+// captureStdout returns what a function prints to the standard output.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-out
+}
+
+// load loads a config from testdata, with sessions in the root directory.
+func (s *tmuxServer) load(name string) client.Config {
+	s.t.Helper()
+	config, err := client.LoadConfig(
+		filepath.Join("testdata", name), map[string]string{"root": s.root})
+	require.NoError(s.t, err)
+	return config
+}
+
+// display returns a tmux format expanded for a target.
+func (s *tmuxServer) display(target, format string) string {
+	s.t.Helper()
+	out, err := exec.Command("tmux", "-S", s.socket, "display-message", "-p", "-t", target, format).Output()
+	require.NoError(s.t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func (s *tmuxServer) windows(session string) []tmux.TmuxWindow {
+	s.t.Helper()
+	windows, err := s.jig.Tmux.ListWindows(tmux.Target{Session: session})
+	require.NoError(s.t, err)
+	return windows
+}
+
+func (s *tmuxServer) panes(session, window string) []tmux.TmuxPane {
+	s.t.Helper()
+	panes, err := s.jig.Tmux.ListPanes(tmux.Target{Session: session, Window: window})
+	require.NoError(s.t, err)
+	return panes
+}
+
+func windowNames(windows []tmux.TmuxWindow) []string {
+	names := []string{}
+	for _, w := range windows {
+		names = append(names, w.Name)
+	}
+	return names
+}
+
+func panePaths(panes []tmux.TmuxPane) []string {
+	paths := []string{}
+	for _, p := range panes {
+		paths = append(paths, p.Path)
+	}
+	return paths
+}
+
+func TestIntegrationStartStop(t *testing.T) {
+	s := newTmuxServer(t)
+	config := s.load("basic.yml")
+	require.NoError(t, s.jig.Start(config, []string{}))
+
+	// The unnamed first window is the session's initial window.
+	windows := s.windows("basic")
+	require.Len(t, windows, 2)
+	assert.Equal(t, "logs", windows[1].Name)
+
+	panes := s.panes("basic", windows[0].ID)
+	assert.Equal(t, []string{s.root, s.root + "/src", s.root + "/docs"}, panePaths(panes))
+	assert.Equal(t, "sources", panes[1].Title)
+
+	// The main pane of the main-vertical layout is as high as the window.
+	assert.Equal(t, s.display(windows[0].ID, "#{window_height}"), s.display(windows[0].ID+".0", "#{pane_height}"))
+
+	logs := s.panes("basic", "logs")
+	assert.Equal(t, []string{s.root + "/logs"}, panePaths(logs))
+	assert.Equal(t, "sleep", logs[0].Command)
+
+	// Manual windows are created when requested explicitly.
+	require.NoError(t, s.jig.Start(config, []string{"manual"}))
+	assert.Equal(t, []string{windows[0].Name, "logs", "manual"}, windowNames(s.windows("basic")))
+
+	require.NoError(t, s.jig.Stop(config, []string{"manual"}))
+	assert.Len(t, s.windows("basic"), 2)
+	require.NoError(t, s.jig.Stop(config, []string{}))
+	assert.False(t, s.jig.Tmux.SessionExists("basic"))
+}
+
+func TestIntegrationBaseIndex(t *testing.T) {
+	s := newTmuxServer(t)
+	require.NoError(t, s.jig.Start(s.load("base-index.yml"), []string{}))
+
+	assert.Equal(t, []string{"one", "two"}, windowNames(s.windows("indexed")))
+	assert.Equal(t, "one", s.display("indexed:1", "#{window_name}"))
+	assert.Equal(t, "two", s.display("indexed:", "#{window_name}"))
+}
+
+func TestIntegrationNestedSessions(t *testing.T) {
+	s := newTmuxServer(t)
+	config := s.load("nested.yml")
+	require.NoError(t, s.jig.Start(config, []string{}))
+
+	for session, window := range map[string]string{"app": "app", "db": "psql", "api": "server"} {
+		assert.Equal(t, []string{window}, windowNames(s.windows(session)), session)
+	}
+	assert.Equal(t, []string{s.root + "/src"}, panePaths(s.panes("api", "server")))
+
+	require.NoError(t, s.jig.Stop(config, []string{}))
+	sessions, err := s.jig.Tmux.ListSessions()
+	if err == nil {
+		assert.Empty(t, sessions)
+	}
+}
+
+func TestIntegrationRoundTrip(t *testing.T) {
+	s := newTmuxServer(t)
+	config := s.load("basic.yml")
+	require.NoError(t, s.jig.Start(config, []string{}))
+
+	// A config printed from the session recreates the same windows and panes.
+	printed, err := s.jig.GenerateSessionConfig("basic")
+	require.NoError(t, err)
+	assert.Equal(t, "basic", printed.Session)
+	assert.Equal(t, s.root, printed.Path)
+
+	windows := s.windows("basic")
+	// The printed process of the logs window would be typed into its shell.
+	printed.Session = "printed"
+	printed.Windows[1].Cmd = ""
+	stdout := captureStdout(t, func() {
+		require.NoError(t, s.jig.Start(printed, []string{}))
+	})
+	// Nothing is printed about the printed panes, e.g. their split types.
+	assert.Empty(t, stdout)
+	assert.Empty(t, s.jig.Out.(*strings.Builder).String())
+
+	reprinted := s.windows("printed")
+	require.Len(t, reprinted, len(windows))
+	for i, w := range windows {
+		assert.Equal(t, w.Name, reprinted[i].Name)
+		assert.Equal(t,
+			panePaths(s.panes("basic", w.ID)),
+			panePaths(s.panes("printed", reprinted[i].ID)),
+		)
+	}
+}
+
+func TestIntegrationRollback(t *testing.T) {
+	s := newTmuxServer(t)
+	require.NoError(t, s.jig.Start(s.load("basic.yml"), []string{}))
+
+	// A session which fails to start is killed, and others are kept.
+	err := s.jig.Start(s.load("broken.yml"), []string{})
+	assert.ErrorContains(t, err, `session "broken": window "two"`)
+	assert.False(t, s.jig.Tmux.SessionExists("broken"))
+	assert.True(t, s.jig.Tmux.SessionExists("basic"))
+
+	s.jig.Options.KeepOnError = true
+	assert.Error(t, s.jig.Start(s.load("broken.yml"), []string{}))
+	assert.Equal(t, []string{"one", "two"}, windowNames(s.windows("broken")))
+}
+
+func TestIntegrationBindings(t *testing.T) {
+	s := newTmuxServer(t)
+	config := client.Config{
+		Session:  "keys",
+		Path:     s.root,
+		Bindings: map[string]string{"r": "display-message mine"},
+	}
+	listKeys := func(table string) string {
+		out, err := exec.Command("tmux", "-S", s.socket, "list-keys", "-T", table, "r").Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	// Another session keeps the server and its bindings once stopped.
+	require.NoError(t, exec.Command("tmux", "-S", s.socket, "-f", "/dev/null",
+		"new-session", "-d", "-s", "other").Run())
+
+	// A command list is stashed and restored as it was bound.
+	require.NoError(t, exec.Command("tmux", "-S", s.socket, "bind-key", "r",
+		"source-file", "/dev/null", `\;`, "display-message", "reloaded").Run())
+	orig := listKeys("prefix")
+	require.Contains(t, orig, `\; display-message reloaded`)
+
+	require.NoError(t, s.jig.Start(config, []string{}))
+	assert.Equal(t, strings.Replace(orig, "prefix", "jig-keys", 1), listKeys("jig-keys"))
+	assert.Contains(t, listKeys("prefix"), `"source-file /dev/null ; display-message reloaded"`)
+
+	require.NoError(t, s.jig.Stop(config, []string{}))
+	assert.Equal(t, orig, listKeys("prefix"))
+}
@@ -1,52 +1,114 @@
 package client
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/rafi/jig/pkg/shell"
 	"github.com/rafi/jig/pkg/tmux"
 )
 
-const DefaultConfigFile = ".jig.yml"
+const (
+	DefaultConfigFile  = ".jig.yml"
+	defaultTmuxCommand = "tmux"
+)
 
 type Options struct {
-	Debug    bool   `help:"Print all commands to ~/.cache/jig.log"`
-	File     string `help:"Custom path to a config file." short:"f"`
-	Detach   bool   `help:"Do not attach to the session." short:"d"`
-	Inside   bool   `help:"Create windows inside current session." short:"i"`
-	TmuxPath string
+	Debug      bool   `help:"Log all commands to ~/.cache/jig.log, or the log file."`
+	File       string `help:"Custom path to a config file." short:"f"`
+	Detach     bool   `help:"Do not attach to the session." short:"d"`
+	Inside     bool   `help:"Create windows inside current session." short:"i"`
+	Jobs       int    `help:"Number of nested sessions to start concurrently, defaults to the number of CPUs." short:"j"`
+	Timings    bool   `help:"Report how long it took to start each session."`
+	Profile    bool   `help:"Report where the time of a start went: hooks, tmux commands, command delays and waits."`
+	NoControl  bool   `help:"Run a tmux process per command, instead of a control mode connection for large sessions."`
+	SocketName string `help:"Name of the tmux server socket, overrides the config." short:"L"`
+	SocketPath string `help:"Path to the tmux server socket, overrides the config." short:"S"`
+	TmuxPath   string `help:"Name or path of the tmux command, e.g. tmate, overrides the config."`
+	TmuxConf   string `help:"Path to the config file of a new tmux server, overrides the config."`
+
+	KeepOnError bool `help:"Keep the sessions and windows created by a start which failed or was interrupted."`
+
+	LogFile   string `help:"Append the log to a file." env:"JIG_LOG" type:"path"`
+	LogLevel  string `help:"Level of the log, one of debug, info, warn or error. Defaults to info, or debug with --debug."`
+	LogFormat string `help:"Format of the log, text or json. Defaults to text."`
 }
 
 var (
-	ErrConfigNotFound   = errors.New("project file not found")
-	ErrEditorNotFound   = errors.New("editor not found")
-	ErrNoWindowsFound   = errors.New("no windows found")
-	ErrNoSessionName    = errors.New("you must specify a session name")
-	ErrNotInsideSession = errors.New("cannot use -i flag outside of a tmux session")
+	ErrConfigNotFound    = errors.New("project file not found")
+	ErrInvalidConfig     = errors.New("invalid config")
+	ErrDependencyCycle   = errors.New("dependency cycle")
+	ErrDependencyFailed  = errors.New("dependency failed to start")
+	ErrUnknownDependency = errors.New("unknown dependency")
+	ErrEditorNotFound    = errors.New("editor not found")
+	ErrInvalidRestart    = errors.New("invalid restart policy")
+	ErrInvalidWait       = errors.New("invalid wait_for condition")
+	ErrWaitTimeout       = errors.New("timed out waiting for")
+	ErrHookTimeout       = errors.New("timed out running")
+	ErrMissingPath       = errors.New("missing directories")
+	ErrBindingConflict   = errors.New("key is bound by another session")
+	ErrNoWindowsFound    = errors.New("no windows found")
+	ErrNoSessionName     = errors.New("you must specify a session name")
+	ErrNotInsideSession  = errors.New("cannot use -i flag outside of a tmux session")
 )
 
 type Jig struct {
-	Tmux      tmux.TmuxClient
+	Tmux      tmux.Client
 	Theme     Theme
 	Options   Options
 	InSession bool
+
+	// Cmd runs shell commands, e.g. before and after commands, and wait
+	// conditions. Defaults to shell.DefaultCommander.
+	Cmd shell.Commander
+
+	// Out receives messages while starting sessions, defaults to stdout.
+	Out io.Writer
+
+	// Logger logs the sessions started and stopped, and the commands run
+	// for them, with the session and window as attributes. Defaults to
+	// discarding records.
+	Logger *slog.Logger
+
+	// Context stops shell commands and waits when it's done, e.g. when jig is
+	// interrupted, and no more windows and sessions are created. Defaults to
+	// context.Background().
+	Context context.Context
+
+	// script receives the commands of a generated script, whose working
+	// directories are made when it runs rather than on this machine.
+	script *shell.ScriptCommander
+
+	// undo records what a start created, to roll it back if it fails.
+	undo *rollback
+
+	// profile records how long the steps of a start took, if profiling.
+	profile *profile
 }
 
-// New creates a new Jig client.
-func New(opts Options, commander shell.Commander) (Jig, error) {
-	if opts.TmuxPath == "" {
-		var err error
-		opts.TmuxPath, err = exec.LookPath("tmux")
-		if err != nil {
-			return Jig{}, err
-		}
+// New creates a new Jig client. The tmux command is looked up once it runs,
+// as a config may select another one.
+func New(opts Options, commander shell.Commander) Jig {
+	bin := defaultTmuxCommand
+	if opts.TmuxPath != "" {
+		bin = filepath.Clean(opts.TmuxPath)
 	}
 	tmux := tmux.TmuxClient{
-		Bin: filepath.Clean(opts.TmuxPath),
-		Cmd: commander,
+		Bin:        bin,
+		Cmd:        commander,
+		SocketName: opts.SocketName,
+		SocketPath: opts.SocketPath,
+		ConfigFile: opts.TmuxConf,
+		Versions:   tmux.NewVersionCache(),
 	}
 	_, inTmuxSession := os.LookupEnv("TMUX")
 
@@ -55,7 +117,97 @@ func New(opts Options, commander shell.Commander) (Jig, error) {
 		Options:   opts,
 		Theme:     NewThemeDefault(),
 		InSession: inTmuxSession,
-	}, nil
+		Cmd:       commander,
+		Out:       os.Stdout,
+	}
+}
+
+// WithConfig returns a client for the tmux command, config file and server
+// selected by a session's config, unless they were selected by the options.
+// Only clients running the tmux command select them. Relative paths are
+// relative to the config file, while a tmux command without a slash is
+// looked up in PATH.
+func (j Jig) WithConfig(session Config) Jig {
+	client, ok := j.Tmux.(tmux.TmuxClient)
+	if !ok {
+		return j
+	}
+	dir := filepath.Dir(session.ConfigPath)
+	if session.TmuxCommand != "" && j.Options.TmuxPath == "" {
+		client.Bin = session.TmuxCommand
+		if strings.HasPrefix(session.TmuxCommand, "~") || strings.Contains(session.TmuxCommand, "/") {
+			client.Bin = shell.ResolvePath(session.TmuxCommand, dir)
+		}
+	}
+	if session.TmuxConf != "" && j.Options.TmuxConf == "" {
+		client.ConfigFile = shell.ResolvePath(session.TmuxConf, dir)
+	}
+
+	noSocket := session.SocketName == "" && session.SocketPath == ""
+	if noSocket || j.Options.SocketName != "" || j.Options.SocketPath != "" {
+		j.Tmux = client
+		return j
+	}
+	client.SocketName = session.SocketName
+	client.SocketPath = ""
+	if session.SocketPath != "" {
+		client.SocketPath = shell.ResolvePath(session.SocketPath, dir)
+	}
+	j.Tmux = client
+	return j
+}
+
+// commander returns the commander of shell commands.
+func (j Jig) commander() shell.Commander {
+	if j.Cmd == nil {
+		return shell.DefaultCommander{}
+	}
+	return j.Cmd
+}
+
+// log returns the client's logger.
+func (j Jig) log() *slog.Logger {
+	if j.Logger == nil {
+		return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.Level(127)}))
+	}
+	return j.Logger
+}
+
+// withLog returns a client which logs with attributes, e.g. the session, as
+// do its shell.DefaultCommander commanders.
+func (j Jig) withLog(args ...any) Jig {
+	if j.Logger == nil {
+		return j
+	}
+	j.Logger = j.Logger.With(args...)
+	if c, ok := j.Cmd.(shell.DefaultCommander); ok {
+		c.Logger = j.Logger
+		j.Cmd = c
+	}
+	if client, ok := j.Tmux.(tmux.TmuxClient); ok {
+		if c, ok := unwrapProfile(client.Cmd).(shell.DefaultCommander); ok {
+			c.Logger = j.Logger
+			client.Cmd = j.profile.wrap(c)
+			j.Tmux = client
+		}
+	}
+	return j
+}
+
+// ctx returns the context of the client's work.
+func (j Jig) ctx() context.Context {
+	if j.Context == nil {
+		return context.Background()
+	}
+	return j.Context
+}
+
+// stdout returns the writer for messages.
+func (j Jig) stdout() io.Writer {
+	if j.Out == nil {
+		return os.Stdout
+	}
+	return j.Out
 }
 
 // SwitchOrAttach switches to a tmux session or attaches to it if it exists.
@@ -70,13 +222,30 @@ func (j Jig) SwitchOrAttach(session string) error {
 }
 
 // execShellCommands executes a list of shell commands in a given directory.
-func (j Jig) execShellCommands(commands []string, path string) error {
+// Each command is stopped once it runs longer than the timeout, if any.
+func (j Jig) execShellCommands(commands []string, path string, timeout time.Duration) error {
 	path = shell.ExpandPath(path)
 	for _, c := range commands {
 		cmd := exec.Command("/bin/sh", "-c", c)
 		cmd.Dir = path
 
-		_, err := j.Tmux.Cmd.Exec(cmd)
+		ctx, cancel := j.ctx(), context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		start := time.Now()
+		_, err := shell.ExecContext(ctx, j.commander(), cmd)
+		cancel()
+		j.profile.add(profileHook, c, start)
+		log := j.log().With("cmd", c, "duration", time.Since(start))
+		if err != nil {
+			log.Warn("command failed", "err", err)
+		} else {
+			log.Info("ran command")
+		}
+		if errors.Is(err, context.DeadlineExceeded) && j.ctx().Err() == nil {
+			return fmt.Errorf("%w %q after %s", ErrHookTimeout, c, timeout)
+		}
 		if err != nil {
 			return err
 		}
@@ -86,10 +255,35 @@ func (j Jig) execShellCommands(commands []string, path string) error {
 
 // Sets a map of environment variables inside a tmux session.
 func (j Jig) setEnvVariables(session string, env map[string]string) error {
-	for key, value := range env {
-		if _, err := j.Tmux.SetEnv(session, key, value); err != nil {
+	for _, key := range sortedKeys(env) {
+		if _, err := j.Tmux.SetEnv(session, key, env[key]); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// setOptions sets a map of tmux options on a target, in a stable order.
+func setOptions(
+	b windowBuilder,
+	target tmux.Target,
+	scope tmux.OptionScope,
+	options map[string]string,
+) error {
+	for _, key := range sortedKeys(options) {
+		if err := b.SetOption(target, scope, key, options[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map in a stable order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
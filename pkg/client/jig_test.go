@@ -1,25 +1,35 @@
 package client_test
 
 import (
+	"context"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/rafi/jig/pkg/client"
 	"github.com/rafi/jig/pkg/tmux"
+	"github.com/rafi/jig/pkg/tmux/tmuxtest"
 )
 
 var homeDir = os.Getenv("HOME")
 
+// mockLock guards mock commanders, which sessions may share concurrently.
+var mockLock sync.Mutex
+
 type MockCommander struct {
 	Commands []string
 	Outputs  []string
 }
 
 func (c *MockCommander) Exec(cmd *exec.Cmd) (string, error) {
+	mockLock.Lock()
+	defer mockLock.Unlock()
 	c.Commands = append(c.Commands, strings.Join(cmd.Args, " "))
 
 	output := ""
@@ -33,6 +43,8 @@ func (c *MockCommander) Exec(cmd *exec.Cmd) (string, error) {
 }
 
 func (c *MockCommander) ExecSilently(cmd *exec.Cmd) error {
+	mockLock.Lock()
+	defer mockLock.Unlock()
 	c.Commands = append(c.Commands, strings.Join(cmd.Args, " "))
 	return nil
 }
@@ -54,13 +66,26 @@ func TestDetectInTmuxSession(t *testing.T) {
 		for key, value := range v.env {
 			os.Setenv(key, value)
 		}
-		j, err := client.New(opts, &MockCommander{})
-		assert.NoError(t, err)
+		j := client.New(opts, &MockCommander{})
 		assert.Equal(t, j.InSession, v.inSession)
 	}
 	os.Clearenv()
 }
 
+// mkdirHome makes a directory in the home directory for a test, unless it
+// exists, and removes it once the test finished.
+func mkdirHome(t *testing.T, name string) {
+	t.Helper()
+	path := filepath.Join(homeDir, name)
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(path) })
+}
+
 func TestStartStopSession(t *testing.T) {
 	testTable := map[string]struct {
 		client           client.Jig
@@ -167,6 +192,207 @@ func TestStartStopSession(t *testing.T) {
 			},
 			[]string{"ses", "win1", "1"},
 		},
+		"test with nested panes and sizes": {
+			client.Jig{Options: client.Options{Detach: true}},
+			client.Config{
+				Session: "ses",
+				Path:    "/tmp",
+				Windows: []client.Window{
+					{
+						Name: "win1",
+						Panes: []client.Pane{
+							{
+								Type:  "horizontal",
+								Size:  "40%",
+								Split: "vertical",
+								Panes: []client.Pane{
+									{Size: "66%", Cmd: "command1"},
+									{Size: "50%"},
+								},
+							},
+							{Type: "vertical", Size: "10"},
+						},
+					},
+				},
+			},
+			[]string{},
+			[]string{
+				"tmux has-session -t ses:",
+				"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+				"tmux split-window -Pd -t ses:win1 -h -l 40% -c /tmp -F #{pane_id}",
+				"tmux split-window -Pd -t ses:win1.%1 -v -l 10 -c /tmp -F #{pane_id}",
+				"tmux split-window -Pd -t ses:win1.%1 -v -l 66% -c /tmp -F #{pane_id}",
+				"tmux send-keys -t ses:win1.%3 -l command1",
+				"tmux send-keys -t ses:win1.%3 Enter",
+				"tmux split-window -Pd -t ses:win1.%3 -v -l 50% -c /tmp -F #{pane_id}",
+			},
+			[]string{
+				"tmux kill-session -t ses:",
+			},
+			[]string{"ses", "$1", "%1", "%2", "%3", "%4"},
+		},
+		"test with custom layout and no panes": {
+			client.Jig{Options: client.Options{Detach: true}},
+			client.Config{
+				Session: "ses",
+				Path:    "/tmp",
+				Windows: []client.Window{
+					{
+						Name:   "win1",
+						Layout: "8be7,80x24,0,0{78x24,0,0,1,1x24,79,0,2}",
+					},
+				},
+			},
+			[]string{},
+			[]string{
+				"tmux has-session -t ses:",
+				"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+				"tmux split-window -Pd -t ses:win1 -v -c /tmp -F #{pane_id}",
+				"tmux select-layout -t ses:win1 tiled",
+				"tmux display-message -p -t ses:win1 #{window_width}" + tmux.ColumnSep + "#{window_height}",
+				"tmux select-layout -t ses:win1 4cde,160x48,0,0{156x48,0,0,1,3x48,157,0,2}",
+			},
+			[]string{
+				"tmux kill-session -t ses:",
+			},
+			[]string{"ses", "$1", "%1", "", "160" + tmux.ColumnSep + "48", ""},
+		},
+		"test with custom layout and fewer panes": {
+			client.Jig{Options: client.Options{Detach: true}},
+			client.Config{
+				Session: "ses",
+				Path:    "/tmp",
+				Windows: []client.Window{
+					{
+						Name:   "win1",
+						Layout: "d67e,80x24,0,0{40x24,0,0,0,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}",
+						Panes:  []client.Pane{{Type: "horizontal"}},
+					},
+				},
+			},
+			[]string{},
+			[]string{
+				"tmux has-session -t ses:",
+				"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+				"tmux split-window -Pd -t ses:win1 -h -c /tmp -F #{pane_id}",
+				"tmux split-window -Pd -t ses:win1 -v -c /tmp -F #{pane_id}",
+				"tmux select-layout -t ses:win1 tiled",
+				"tmux display-message -p -t ses:win1 #{window_width}" + tmux.ColumnSep + "#{window_height}",
+				"tmux select-layout -t ses:win1 7f31,160x48,0,0{80x48,0,0,0,79x48,81,0[79x24,81,0,1,79x23,81,25,2]}",
+			},
+			[]string{
+				"tmux kill-session -t ses:",
+			},
+			[]string{"ses", "$1", "%1", "%2", "", "160" + tmux.ColumnSep + "48", ""},
+		},
+		"test with session and window options": {
+			client.Jig{Options: client.Options{Detach: true}},
+			client.Config{
+				Session:       "ses",
+				Path:          "/tmp",
+				Options:       map[string]string{"mouse": "on", "base-index": "1"},
+				WindowOptions: map[string]string{"remain-on-exit": "on", "synchronize-panes": "on"},
+				Windows: []client.Window{
+					{
+						Name:     "win1",
+						Commands: []string{"command1"},
+						Options:  map[string]string{"synchronize-panes": "off"},
+					},
+				},
+			},
+			[]string{},
+			[]string{
+				"tmux has-session -t ses:",
+				"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+				"tmux set-option -t ses: base-index 1",
+				"tmux set-option -t ses: mouse on",
+				"tmux send-keys -t ses:win1 -l command1",
+				"tmux send-keys -t ses:win1 Enter",
+				"tmux set-option -w -t ses:win1 remain-on-exit on",
+				"tmux set-option -w -t ses:win1 synchronize-panes off",
+				"tmux move-window -r -s ses -t ses",
+			},
+			[]string{
+				"tmux kill-session -t ses:",
+			},
+			[]string{"ses", "$1"},
+		},
+		"test with pane titles": {
+			client.Jig{Options: client.Options{Detach: true}},
+			client.Config{
+				Session:    "ses",
+				Path:       "/tmp",
+				PaneTitles: "top",
+				Windows: []client.Window{
+					{
+						Name:  "win1",
+						Title: "api",
+						Panes: []client.Pane{
+							{Type: "horizontal", Title: "worker"},
+						},
+					},
+				},
+			},
+			[]string{},
+			[]string{
+				"tmux has-session -t ses:",
+				"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+				"tmux select-pane -t ses:win1 -T api",
+				"tmux split-window -Pd -t ses:win1 -h -c /tmp -F #{pane_id}",
+				"tmux select-pane -t ses:win1.%1 -T worker",
+				"tmux set-option -w -t ses:win1 pane-border-format  #{pane_title} ",
+				"tmux set-option -w -t ses:win1 pane-border-status top",
+			},
+			[]string{
+				"tmux kill-session -t ses:",
+			},
+			[]string{"ses", "$1", "%1"},
+		},
+		"test with processes": {
+			client.Jig{Options: client.Options{Detach: true}},
+			client.Config{
+				Session: "ses",
+				Path:    "/tmp",
+				Windows: []client.Window{
+					{
+						Name:    "win1",
+						Process: client.Process{Run: "htop"},
+					},
+					{
+						Name: "win2",
+						Process: client.Process{
+							Run:     "make serve",
+							Restart: client.RestartOnFailure,
+						},
+						Panes: []client.Pane{
+							{
+								Type: "horizontal",
+								Process: client.Process{
+									Run:          "tail -f log",
+									RemainOnExit: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			[]string{},
+			[]string{
+				"tmux has-session -t ses:",
+				"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp htop",
+				"tmux new-window -Pd -t ses: -n win2 -F #{window_id} -c /tmp",
+				"tmux set-option -p -t ses:@2 remain-on-exit on",
+				"tmux set-hook -p -t ses:@2 pane-died if-shell -F '#{!=:#{pane_dead_status},0}' 'run-shell -d 1 ; respawn-pane'",
+				"tmux respawn-pane -k -t ses:@2 make serve",
+				"tmux split-window -Pd -t ses:@2 -h -c /tmp -F #{pane_id}",
+				"tmux set-option -p -t ses:@2.%3 remain-on-exit on",
+				"tmux respawn-pane -k -t ses:@2.%3 tail -f log",
+			},
+			[]string{
+				"tmux kill-session -t ses:",
+			},
+			[]string{"ses", "$1", "@2", "%3"},
+		},
 		"test start windows from option's Windows parameter": {
 			client.Jig{},
 			client.Config{
@@ -272,6 +498,26 @@ func TestStartStopSession(t *testing.T) {
 			},
 			[]string{"ses", ""},
 		},
+		"test with socket name": {
+			client.Jig{Options: client.Options{Detach: true}},
+			client.Config{
+				Session:    "ses",
+				Path:       "/tmp",
+				SocketName: "work",
+				Windows: []client.Window{
+					{Name: "win1"},
+				},
+			},
+			[]string{},
+			[]string{
+				"tmux -L work has-session -t ses:",
+				"tmux -L work new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+			},
+			[]string{
+				"tmux -L work kill-session -t ses:",
+			},
+			[]string{"ses", "win1"},
+		},
 		"test create new windows in current session with different name": {
 			client.Jig{Options: client.Options{Inside: true}, InSession: true},
 			client.Config{
@@ -294,10 +540,15 @@ func TestStartStopSession(t *testing.T) {
 		},
 	}
 
+	// Working directories are checked on this machine, whichever commander
+	// runs shell commands.
+	mkdirHome(t, "root")
+
 	for testDescription, params := range testTable {
 		t.Run("start session: "+testDescription, func(t *testing.T) {
 			commander := &MockCommander{[]string{}, params.commanderOutputs}
 			params.client.Tmux = tmux.TmuxClient{Bin: "tmux", Cmd: commander}
+			params.client.Cmd = commander
 			assert.NoError(t, params.client.Start(params.config, params.windows))
 			assert.Equal(t, params.startCommands, commander.Commands)
 		})
@@ -305,8 +556,368 @@ func TestStartStopSession(t *testing.T) {
 		t.Run("stop session: "+testDescription, func(t *testing.T) {
 			commander := &MockCommander{[]string{}, params.commanderOutputs}
 			params.client.Tmux = tmux.TmuxClient{Bin: "tmux", Cmd: commander}
+			params.client.Cmd = commander
 			assert.NoError(t, params.client.Stop(params.config, params.windows))
 			assert.Equal(t, params.stopCommands, commander.Commands)
 		})
 	}
 }
+
+func TestWithConfig(t *testing.T) {
+	serverArgs := func(j client.Jig) []string {
+		return j.Tmux.(tmux.TmuxClient).ServerArgs()
+	}
+	config := client.Config{SocketPath: "~/tmux.sock"}
+
+	jig := client.Jig{Tmux: tmux.TmuxClient{}}.WithConfig(config)
+	assert.Equal(t, []string{"-S", homeDir + "/tmux.sock"}, serverArgs(jig))
+
+	// A server selected by the options takes precedence over the config.
+	jig = client.Jig{
+		Tmux:    tmux.TmuxClient{SocketName: "personal"},
+		Options: client.Options{SocketName: "personal"},
+	}
+	assert.Equal(t, []string{"-L", "personal"}, serverArgs(jig.WithConfig(config)))
+
+	// Sessions without a server keep their parent's.
+	jig = jig.WithConfig(client.Config{})
+	assert.Equal(t, []string{"-L", "personal"}, serverArgs(jig))
+
+	config = client.Config{TmuxCommand: "tmate", TmuxConf: "/tmp/tmate.conf"}
+	jig = client.Jig{Tmux: tmux.TmuxClient{Bin: "tmux"}}.WithConfig(config)
+	assert.Equal(t, "tmate", jig.Tmux.(tmux.TmuxClient).Bin)
+	assert.Equal(t, []string{"-f", "/tmp/tmate.conf"}, serverArgs(jig))
+
+	// Paths are relative to the config file, wherever jig runs.
+	relative := client.Config{
+		ConfigPath:  "/src/.jig.yml",
+		TmuxCommand: "bin/tmux",
+		TmuxConf:    "tmux.conf",
+		SocketPath:  "tmp/tmux.sock",
+	}
+	jig = client.Jig{Tmux: tmux.TmuxClient{Bin: "tmux"}}.WithConfig(relative)
+	assert.Equal(t, "/src/bin/tmux", jig.Tmux.(tmux.TmuxClient).Bin)
+	assert.Equal(t, []string{"-f", "/src/tmux.conf", "-S", "/src/tmp/tmux.sock"}, serverArgs(jig))
+
+	jig = client.Jig{
+		Tmux:    tmux.TmuxClient{Bin: "/usr/bin/tmux", ConfigFile: "/tmp/tmux.conf"},
+		Options: client.Options{TmuxPath: "/usr/bin/tmux", TmuxConf: "/tmp/tmux.conf"},
+	}
+	jig = jig.WithConfig(config)
+	assert.Equal(t, "/usr/bin/tmux", jig.Tmux.(tmux.TmuxClient).Bin)
+	assert.Equal(t, []string{"-f", "/tmp/tmux.conf"}, serverArgs(jig))
+}
+
+func TestStartUnsupportedFeature(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		config  client.Config
+		feature string
+	}{
+		{
+			name:    "restarted process",
+			version: "tmux 2.9a",
+			config: client.Config{Windows: []client.Window{
+				{Name: "win1", Process: client.Process{Run: "server", Restart: client.RestartAlways}},
+			}},
+			feature: "per-pane options",
+		},
+		{
+			name:    "pane env",
+			version: "tmux 2.9a",
+			config: client.Config{Windows: []client.Window{
+				{Name: "win1", Panes: []client.Pane{{Process: client.Process{Env: map[string]string{"A": "1"}}}}},
+			}},
+			feature: "per-pane env",
+		},
+		{
+			name:    "first window env",
+			version: "tmux 2.9a",
+			config: client.Config{Windows: []client.Window{
+				{Name: "win1", Process: client.Process{Env: map[string]string{"A": "1"}}},
+			}},
+			feature: "per-pane env",
+		},
+		{
+			name:    "popup binding",
+			version: "tmux 3.1c",
+			config: client.Config{Bindings: map[string]string{
+				"T": `if-shell -F "#{pane_in_mode}" "display-popup -E htop"`,
+			}},
+			feature: "popups",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			commander := &MockCommander{[]string{}, []string{test.version}}
+			jig := client.Jig{
+				Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander, Versions: tmux.NewVersionCache()},
+				Options: client.Options{Detach: true},
+			}
+			test.config.Session = "ses"
+			test.config.Path = "/tmp"
+
+			// Sessions aren't created if tmux doesn't support what they need.
+			err := jig.Start(test.config, []string{})
+			assert.ErrorIs(t, err, tmux.ErrUnsupportedVersion)
+			assert.ErrorContains(t, err, "for "+test.feature+",")
+			assert.Equal(t, []string{"tmux -V"}, commander.Commands)
+		})
+	}
+}
+
+func TestStartEnv(t *testing.T) {
+	server := tmuxtest.NewServer()
+	jig := client.Jig{Tmux: server, Cmd: &MockCommander{}, Options: client.Options{Detach: true}}
+	env := map[string]string{"PORT": "8080"}
+	config := client.Config{
+		Session: "ses",
+		Path:    t.TempDir(),
+		Windows: []client.Window{
+			{Name: "win1", Process: client.Process{Env: env}},
+			{
+				Name:    "win2",
+				Process: client.Process{Env: env},
+				Panes: []client.Pane{{Process: client.Process{
+					Run:     "make serve",
+					Restart: client.RestartOnFailure,
+					Env:     map[string]string{"DEBUG": "1", "LOG": "a b"},
+				}}},
+			},
+		},
+	}
+	assert.NoError(t, jig.Start(config, []string{}))
+
+	assert.Equal(t, env, server.Pane(tmux.Target{Session: "ses", Window: "win1"}).Env)
+	panes := server.Window(tmux.Target{Session: "ses", Window: "win2"}).Panes
+	assert.Equal(t, env, panes[0].Env)
+
+	// Panes inherit their window's environment variables, and are respawned
+	// with them, as tmux doesn't keep them.
+	assert.Equal(t, map[string]string{"DEBUG": "1", "LOG": "a b", "PORT": "8080"}, panes[1].Env)
+	assert.Equal(t,
+		`if-shell -F '#{!=:#{pane_dead_status},0}' 'run-shell -d 1 ; respawn-pane -e DEBUG=1 -e '\''LOG=a b'\'' -e PORT=8080'`,
+		panes[1].Hooks["pane-died"])
+}
+
+func TestStartLayoutTooFewPanes(t *testing.T) {
+	server := tmuxtest.NewServer()
+	jig := client.Jig{Tmux: server, Cmd: &MockCommander{}, Options: client.Options{Detach: true}}
+	config := client.Config{
+		Session: "ses",
+		Path:    t.TempDir(),
+		Windows: []client.Window{
+			{
+				Name:   "win1",
+				Layout: "8be7,80x24,0,0{78x24,0,0,1,1x24,79,0,2}",
+				Panes:  []client.Pane{{Type: "horizontal"}, {Type: "vertical"}},
+			},
+		},
+	}
+
+	err := jig.Start(config, []string{})
+	assert.ErrorIs(t, err, tmux.ErrInvalidLayout)
+	assert.ErrorContains(t, err, "layout has 2 panes, window has 3")
+}
+
+func TestStartInvalidSplitType(t *testing.T) {
+	for _, w := range []client.Window{
+		{Name: "win1", Panes: []client.Pane{{Type: "diagonal"}}},
+		{Name: "win1", Panes: []client.Pane{{Split: "diagonal"}}},
+		{Name: "win1", Split: "diagonal"},
+	} {
+		server := tmuxtest.NewServer()
+		jig := client.Jig{Tmux: server, Cmd: &MockCommander{}, Options: client.Options{Detach: true}}
+		config := client.Config{Session: "ses", Path: t.TempDir(), Windows: []client.Window{w}}
+		err := jig.Start(config, []string{})
+		assert.ErrorIs(t, err, tmux.ErrInvalidSplitType)
+		assert.ErrorContains(t, err, `"diagonal", expected horizontal or vertical`)
+		assert.False(t, server.SessionExists("ses"))
+	}
+}
+
+func TestStartStopServer(t *testing.T) {
+	server := tmuxtest.NewServer()
+	jig := client.Jig{Tmux: server, Cmd: &MockCommander{}}
+	config := client.Config{
+		Session: "ses",
+		Path:    "/tmp",
+		Env:     map[string]string{"EDITOR": "vim"},
+		Options: map[string]string{"base-index": "1"},
+		Windows: []client.Window{
+			{
+				Name:     "code",
+				Commands: []string{"git status"},
+				Panes: []client.Pane{
+					{Type: "horizontal", Size: "30%", Title: "logs", Process: client.Process{Run: "tail -f log"}},
+					{Type: "vertical", Path: "/var", Focus: true},
+				},
+			},
+			{Name: "shell", Layout: tmux.LayoutTiled, Focus: true},
+		},
+	}
+	assert.NoError(t, jig.Start(config, []string{}))
+
+	session := server.Session("ses")
+	assert.Equal(t, "ses", server.Attached)
+	assert.Equal(t, "1", session.Options["base-index"])
+	assert.Equal(t, "vim", session.Env["EDITOR"])
+
+	windows, err := server.ListWindows(tmux.Target{Session: "ses"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"code", "shell"}, []string{windows[0].Name, windows[1].Name})
+	assert.Equal(t, []int{1, 2}, []int{session.Windows[0].Index, session.Windows[1].Index})
+	assert.True(t, session.Windows[1].Active)
+	assert.Equal(t, tmux.LayoutTiled, session.Windows[1].Layout)
+
+	panes := session.Windows[0].Panes
+	assert.Len(t, panes, 3)
+	assert.Equal(t, []string{"git status"}, panes[0].Keys)
+	assert.Equal(t, "tail -f log", panes[1].Command)
+	assert.Equal(t, "logs", panes[1].Title)
+	assert.Equal(t, "30%", panes[1].Size)
+	assert.Equal(t, "/var", panes[2].Path)
+	assert.True(t, panes[2].Active)
+
+	assert.NoError(t, jig.Stop(config, []string{}))
+	assert.False(t, server.SessionExists("ses"))
+}
+
+// interruptingCommander cancels a context when it runs a command.
+type interruptingCommander struct {
+	MockCommander
+	cancel context.CancelFunc
+}
+
+func (c *interruptingCommander) ExecSilently(cmd *exec.Cmd) error {
+	c.cancel()
+	return c.MockCommander.ExecSilently(cmd)
+}
+
+func TestStartInterrupted(t *testing.T) {
+	config := client.Config{
+		Session: "ses",
+		Path:    "/tmp",
+		Windows: []client.Window{
+			{Name: "one"},
+			{Name: "two", Process: client.Process{WaitFor: &client.WaitFor{Command: "pg_isready"}}},
+			{Name: "three"},
+		},
+	}
+	start := func(opts client.Options) (*tmuxtest.Server, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		server := tmuxtest.NewServer()
+		jig := client.Jig{
+			Tmux:    server,
+			Cmd:     &interruptingCommander{cancel: cancel},
+			Options: opts,
+			Context: ctx,
+		}
+		return server, jig.Start(config, []string{})
+	}
+
+	// The partially created session is killed.
+	server, err := start(client.Options{Detach: true})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, server.SessionExists("ses"))
+
+	server, err = start(client.Options{Detach: true, KeepOnError: true})
+	assert.ErrorIs(t, err, context.Canceled)
+	windows, err := server.ListWindows(tmux.Target{Session: "ses"})
+	assert.NoError(t, err)
+	assert.Len(t, windows, 2)
+}
+
+func TestStartBeforeTimeout(t *testing.T) {
+	server := tmuxtest.NewServer()
+	jig := client.Jig{Tmux: server, Options: client.Options{Detach: true}}
+	config := client.Config{
+		Session:       "ses",
+		Path:          t.TempDir(),
+		Before:        []string{"sleep 10"},
+		BeforeTimeout: 50 * time.Millisecond,
+	}
+
+	err := jig.Start(config, []string{})
+	assert.ErrorIs(t, err, client.ErrHookTimeout)
+	assert.EqualError(t, err, `session "ses": before: timed out running "sleep 10" after 50ms`)
+	assert.False(t, server.SessionExists("ses"))
+}
+
+func TestStartRollback(t *testing.T) {
+	missing := &client.WaitFor{File: "missing", Timeout: 10 * time.Millisecond, Interval: time.Millisecond}
+	config := client.Config{
+		Session: "ses",
+		Path:    t.TempDir(),
+		Before:  []string{"up"},
+		After:   []string{"down"},
+		Windows: []client.Window{
+			{Name: "one"},
+			{Name: "two", Process: client.Process{WaitFor: missing}},
+			{Name: "three"},
+		},
+	}
+	server := tmuxtest.NewServer()
+	commander := &MockCommander{}
+	jig := client.Jig{Tmux: server, Cmd: commander, Options: client.Options{Detach: true}}
+
+	// The session is killed, and the after commands run as before ran.
+	err := jig.Start(config, []string{})
+	assert.ErrorIs(t, err, client.ErrWaitTimeout)
+	assert.ErrorContains(t, err, `session "ses": window "two": start process: timed out waiting for file missing`)
+	assert.False(t, server.SessionExists("ses"))
+	assert.Equal(t, []string{"/bin/sh -c up", "/bin/sh -c down"}, commander.Commands)
+
+	jig.Options.KeepOnError = true
+	assert.Error(t, jig.Start(config, []string{}))
+	windows, err := server.ListWindows(tmux.Target{Session: "ses"})
+	assert.NoError(t, err)
+	assert.Len(t, windows, 2)
+
+	// Only windows created in an existing session are killed.
+	jig.Options.KeepOnError = false
+	assert.Error(t, jig.Start(config, []string{"two", "three"}))
+	rolledBack, err := server.ListWindows(tmux.Target{Session: "ses"})
+	assert.NoError(t, err)
+	assert.Equal(t, windows, rolledBack)
+}
+
+func TestStartProfile(t *testing.T) {
+	dir := t.TempDir()
+	config := client.Config{
+		Session:      "ses",
+		Path:         dir,
+		CommandDelay: 5,
+		Before:       []string{"up"},
+		Windows: []client.Window{
+			{Name: "one", Cmd: "make", Process: client.Process{WaitFor: &client.WaitFor{File: dir}}},
+		},
+	}
+	out := &strings.Builder{}
+	jig := client.Jig{
+		Tmux:    tmuxtest.NewServer(),
+		Cmd:     &MockCommander{},
+		Options: client.Options{Detach: true, Profile: true},
+		Out:     out,
+	}
+	assert.NoError(t, jig.Start(config, []string{}))
+	assert.Regexp(t, `^Profile of 3 steps in \d+m?s:\n`+
+		`  hook +1 +\d+m?s\n`+
+		`  delay +1 +\d+ms\n`+
+		`  wait +1 +\d+m?s\n`+
+		`Slowest:\n`, out.String())
+	assert.Contains(t, out.String(), "hook   up\n")
+	assert.Contains(t, out.String(), "delay  ses:one\n")
+	assert.Contains(t, out.String(), "wait   file "+dir+"\n")
+
+	// Commands of a tmux client are recorded.
+	out.Reset()
+	commander := &MockCommander{[]string{}, []string{"ses"}}
+	jig.Tmux = tmux.TmuxClient{Bin: "/usr/bin/tmux", Cmd: commander}
+	jig.Cmd = commander
+	assert.NoError(t, jig.Start(client.Config{Session: "ses", Path: dir}, []string{}))
+	assert.Regexp(t, `\n  tmux +2 +\d+m?s\n`, out.String())
+	assert.Contains(t, out.String(), "tmux   tmux has-session -t ses:\n")
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// startSessions starts sessions concurrently, each one after the sessions it
+// depends on were started, with at most Options.Jobs sessions at a time.
+// Sessions which depend on a failed session are not started, and no more
+// sessions are started once the client's context is done. The output of
+// each session is written in the order of the sessions.
+func (j Jig) startSessions(sessions []Config, windows []string) error {
+	order, err := sessionOrder(sessions)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(sessions))
+	for i, s := range sessions {
+		index[s.Session] = i
+	}
+	done := make([]chan struct{}, len(sessions))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	jobs := j.Options.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	slots := make(chan struct{}, jobs)
+	out := newOrderedOutput(j.stdout(), len(sessions))
+
+	errs := make([]error, len(sessions))
+	var wg sync.WaitGroup
+	for _, i := range order {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+			defer out.done(i)
+			for _, dep := range sessions[i].DependsOn {
+				<-done[index[dep]]
+				if errs[index[dep]] != nil {
+					errs[i] = fmt.Errorf("%w: %q", ErrDependencyFailed, dep)
+					return
+				}
+			}
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if errs[i] = j.ctx().Err(); errs[i] != nil {
+				return
+			}
+			session := j
+			session.Out = out.writer(i)
+			errs[i] = session.startTimedSession(sessions[i], windows)
+		}(i)
+	}
+	wg.Wait()
+	if err := j.ctx().Err(); err != nil {
+		return err
+	}
+
+	// Report the failed sessions, rather than the ones which were skipped.
+	failed := []error{}
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrDependencyFailed) {
+			failed = append(failed, err)
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// startTimedSession starts a session, and reports how long it took if
+// timings are enabled.
+func (j Jig) startTimedSession(session Config, windows []string) error {
+	start := time.Now()
+	if err := j.startSession(session, windows); err != nil {
+		if session.Session == "" {
+			return err
+		}
+		return fmt.Errorf("session %q: %w", session.Session, err)
+	}
+	j.log().Info("started session", "session", session.Session, "duration", time.Since(start))
+	if j.Options.Timings {
+		fmt.Fprintf(j.stdout(), "Started %q in %s\n", session.Session, since(start))
+	}
+	return nil
+}
+
+// since returns the rounded duration since a time.
+func since(start time.Time) time.Duration {
+	return time.Since(start).Round(time.Millisecond)
+}
+
+// orderedOutput writes the output of concurrent jobs in their order. The
+// output of the first unfinished job is written as-is, and the output of
+// the following jobs is buffered until it's their turn.
+type orderedOutput struct {
+	mu       sync.Mutex
+	w        io.Writer
+	buffers  []bytes.Buffer
+	finished []bool
+	next     int
+}
+
+func newOrderedOutput(w io.Writer, jobs int) *orderedOutput {
+	return &orderedOutput{
+		w:        w,
+		buffers:  make([]bytes.Buffer, jobs),
+		finished: make([]bool, jobs),
+	}
+}
+
+// writer returns the writer of a job.
+func (o *orderedOutput) writer(job int) io.Writer {
+	return jobWriter{o, job}
+}
+
+// done marks a job as finished and writes the buffered output of the jobs
+// which are next in turn.
+func (o *orderedOutput) done(job int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.finished[job] = true
+	for o.next < len(o.buffers) {
+		_, _ = o.buffers[o.next].WriteTo(o.w)
+		if !o.finished[o.next] {
+			break
+		}
+		o.next++
+	}
+}
+
+type jobWriter struct {
+	o   *orderedOutput
+	job int
+}
+
+func (w jobWriter) Write(p []byte) (int, error) {
+	w.o.mu.Lock()
+	defer w.o.mu.Unlock()
+	if w.job == w.o.next {
+		return w.o.w.Write(p)
+	}
+	return w.o.buffers[w.job].Write(p)
+}
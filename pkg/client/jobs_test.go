@@ -0,0 +1,87 @@
+package client_test
+
+import (
+	"bytes"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// SlowCommander creates sessions slowly, and records how many were created
+// at the same time.
+type SlowCommander struct {
+	MockCommander
+	mu      sync.Mutex
+	running int
+	Max     int
+}
+
+func (c *SlowCommander) Exec(cmd *exec.Cmd) (string, error) {
+	if !slices.Contains(cmd.Args, "new-session") {
+		return c.MockCommander.Exec(cmd)
+	}
+	c.mu.Lock()
+	c.running++
+	c.Max = max(c.Max, c.running)
+	c.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	return c.MockCommander.Exec(cmd)
+}
+
+func TestJobs(t *testing.T) {
+	config := client.Config{
+		Session: "ses",
+		Path:    "/tmp",
+		Sessions: []client.Config{
+			{Session: "one", Path: "/tmp"},
+			{Session: "two", Path: "/tmp"},
+			{Session: "three", Path: "/tmp"},
+			{Session: "four", Path: "/tmp"},
+		},
+	}
+
+	for _, jobs := range []int{1, 2, 4} {
+		commander := &SlowCommander{MockCommander: MockCommander{[]string{}, []string{"ses"}}}
+		jig := client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Cmd:     commander,
+			Options: client.Options{Detach: true, Jobs: jobs},
+			Out:     &bytes.Buffer{},
+		}
+		assert.NoError(t, jig.Start(config, []string{}))
+		assert.Equal(t, jobs, commander.Max, "jobs: %d", jobs)
+	}
+
+	t.Run("timings in order", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		commander := &SlowCommander{MockCommander: MockCommander{[]string{}, []string{"ses"}}}
+		jig := client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Cmd:     commander,
+			Options: client.Options{Detach: true, Jobs: 4, Timings: true},
+			Out:     out,
+		}
+		assert.NoError(t, jig.Start(config, []string{}))
+		assert.Regexp(t, regexp.MustCompile(`^`+
+			`Started "one" in \d+ms\n`+
+			`Started "two" in \d+ms\n`+
+			`Started "three" in \d+ms\n`+
+			`Started "four" in \d+ms\n`+
+			`Started "ses" in \d+ms\n`+
+			`Started 5 sessions in \d+ms\n$`,
+		), out.String())
+	})
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rafi/jig/pkg/shell"
+)
+
+// workdir is the working directory of a session, window or pane, and how
+// to make it if it's missing.
+type workdir struct {
+	path   string
+	source PathSource
+	owner  string
+}
+
+// workdirs returns the working directories of a session, and of the windows
+// and panes which will be created, as they're resolved to create them.
+func workdirs(session Config, windows []string) []workdir {
+	dirs := []workdir{{session.Path, session.PathSource, "session"}}
+	var addPanes func(panes []Pane, parent, owner string)
+	addPanes = func(panes []Pane, parent, owner string) {
+		for i, p := range panes {
+			path := resolvePath(p.Path, parent)
+			name := fmt.Sprintf("%s: pane %d", owner, i+1)
+			dirs = append(dirs, workdir{path, p.PathSource, name})
+			addPanes(p.Panes, path, name)
+		}
+	}
+	for i, w := range session.Windows {
+		if skipWindow(w, windows) {
+			continue
+		}
+		path := resolvePath(w.Path, session.Path)
+		dirs = append(dirs, workdir{path, w.PathSource, windowStep(i, w)})
+		addPanes(w.Panes, path, windowStep(i, w))
+	}
+	return dirs
+}
+
+// prepareWorkdirs makes the missing working directories of a session, and
+// checks that none are missing.
+func (j Jig) prepareWorkdirs(session Config, windows []string) error {
+	if err := j.makeWorkdirs(session, windows); err != nil {
+		return err
+	}
+	return j.checkWorkdirs(session, windows)
+}
+
+// makeWorkdirs makes the missing working directories of a session which
+// have a source, by cloning or creating them. They're made on this machine,
+// or by a script when it runs.
+func (j Jig) makeWorkdirs(session Config, windows []string) error {
+	if j.script != nil {
+		j.scriptWorkdirs(j.script, session, windows)
+		return nil
+	}
+	for _, d := range workdirs(session, windows) {
+		if isDir(d.path) {
+			continue
+		}
+		switch {
+		case d.source.Clone != "":
+			if err := j.clone(session, d); err != nil {
+				return fmt.Errorf("%s: clone %s: %w", d.owner, d.source.Clone, err)
+			}
+		case d.source.CreatePath:
+			if err := os.MkdirAll(d.path, 0o755); err != nil {
+				return fmt.Errorf("%s: %w", d.owner, err)
+			}
+			j.log().Info("created directory", "path", d.path)
+		}
+	}
+	return nil
+}
+
+// scriptWorkdirs writes the commands which make the missing working
+// directories of a session to a script.
+func (j Jig) scriptWorkdirs(script *shell.ScriptCommander, session Config, windows []string) {
+	seen := map[string]bool{}
+	for _, d := range workdirs(session, windows) {
+		if seen[d.path] {
+			continue
+		}
+		seen[d.path] = true
+		path := shell.Quote(d.path)
+		switch {
+		case d.source.Clone != "":
+			script.Println(fmt.Sprintf("[ -d %s ] || git clone -- %s %s",
+				path, shell.Quote(cloneSource(session, d)), path))
+		case d.source.CreatePath:
+			script.Println("mkdir -p " + path)
+		}
+	}
+}
+
+// checkWorkdirs returns an error listing the missing working directories of
+// a session, as tmux would start their panes in the home directory instead.
+// A script's directories aren't checked, as they're made when it runs.
+func (j Jig) checkWorkdirs(session Config, windows []string) error {
+	if j.script != nil {
+		return nil
+	}
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, d := range workdirs(session, windows) {
+		if seen[d.path] || isDir(d.path) {
+			continue
+		}
+		seen[d.path] = true
+		missing = append(missing, fmt.Sprintf("%s (%s)", d.path, d.owner))
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingPath, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// clone clones the git repository of a working directory into it.
+func (j Jig) clone(session Config, d workdir) error {
+	source := cloneSource(session, d)
+	cmd := exec.Command("git", "clone", "--", source, d.path)
+	if _, err := shell.ExecContext(j.ctx(), j.commander(), cmd); err != nil {
+		return err
+	}
+	j.log().Info("cloned repository", "path", d.path, "source", source)
+	return nil
+}
+
+// cloneSource returns the repository a working directory is cloned from.
+// Local repositories are relative to the session's config file.
+func cloneSource(session Config, d workdir) string {
+	if isLocalRepository(d.source.Clone) {
+		return shell.ResolvePath(d.source.Clone, filepath.Dir(session.ConfigPath))
+	}
+	return d.source.Clone
+}
+
+// isLocalRepository returns true if a repository to clone is a local path,
+// rather than a URL or an scp-like address, e.g. git@host:repo.git, as git
+// tells them apart.
+func isLocalRepository(source string) bool {
+	if strings.Contains(source, "://") {
+		return false
+	}
+	colon := strings.Index(source, ":")
+	slash := strings.Index(source, "/")
+	return colon < 0 || (slash >= 0 && slash < colon)
+}
+
+// isDir returns true if a path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux/tmuxtest"
+)
+
+func TestStartWorkdirs(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "project")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	config := client.Config{
+		Session:    "ses",
+		Path:       dir,
+		ConfigPath: filepath.Join(dir, ".jig.yml"),
+		Before:     []string{"touch up"},
+		After:      []string{"touch down"},
+		Windows: []client.Window{
+			{Name: "one", Path: "src", Panes: []client.Pane{{Path: "docs"}, {Path: "/"}}},
+			{Name: "two", Path: "src"},
+		},
+	}
+	server := tmuxtest.NewServer()
+	jig := client.Jig{Tmux: server, Cmd: shell.DefaultCommander{}, Options: client.Options{Detach: true}}
+
+	// Missing directories are listed once, before the session is created,
+	// and the after commands run as the before commands did.
+	err := jig.Start(config, []string{})
+	assert.ErrorIs(t, err, client.ErrMissingPath)
+	assert.EqualError(t, err, `session "ses": missing directories: `+
+		dir+`/src (window "one"), `+dir+`/src/docs (window "one": pane 1)`)
+	assert.False(t, server.SessionExists("ses"))
+	assert.FileExists(t, dir+"/down")
+
+	// Directories are checked whichever commander runs shell commands.
+	mock := jig
+	mock.Cmd = &MockCommander{[]string{}, []string{}}
+	assert.ErrorIs(t, mock.Start(config, []string{}), client.ErrMissingPath)
+	assert.False(t, server.SessionExists("ses"))
+
+	// Directories are cloned, or created with their parents, before the
+	// before commands run.
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	require.NoError(t, exec.Command("git", "init", "-q", "--bare", filepath.Join(root, "mirror.git")).Run())
+	config.Before = []string{"test -d src"}
+	config.Windows[0].Clone = "../mirror.git"
+	config.Windows[0].Panes[0].Path = "docs/api"
+	config.Windows[0].Panes[0].CreatePath = true
+	assert.NoError(t, jig.Start(config, []string{}))
+	assert.DirExists(t, dir+"/src/.git")
+	assert.DirExists(t, dir+"/src/docs/api")
+	assert.True(t, server.SessionExists("ses"))
+}
+
+func TestScriptWorkdirs(t *testing.T) {
+	// Directories are made by the script, rather than checked or made on
+	// this machine.
+	dir := filepath.Join(t.TempDir(), "missing")
+	config := client.Config{
+		Session:    "ses",
+		Path:       dir,
+		ConfigPath: "/src/.jig.yml",
+		PathSource: client.PathSource{CreatePath: true},
+		Windows: []client.Window{
+			{Name: "one", Path: "app", PathSource: client.PathSource{Clone: "../app.git"}},
+			{Name: "two", Path: "app"},
+		},
+	}
+
+	expected := `#!/bin/sh
+# Generated by jig from /src/.jig.yml
+set -e
+
+if ! tmux has-session -t ses: 2>/dev/null; then
+	mkdir -p ` + dir + `
+	[ -d ` + dir + `/app ] || git clone -- /app.git ` + dir + `/app
+	jig_1=$(tmux new-session -Pd -F '#{session_id}' -s ses -n one -c ` + dir + `/app)
+	jig_2=$(tmux new-window -Pd -t ses: -n two -F '#{window_id}' -c ` + dir + `/app)
+fi
+`
+
+	jig := client.Jig{Options: client.Options{Detach: true}}
+	script, err := jig.Script(config, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, script)
+	assert.NoDirExists(t, dir)
+}
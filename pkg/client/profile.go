@@ -0,0 +1,159 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// profileSlowest is the number of slowest steps a profile reports.
+const profileSlowest = 10
+
+// Kinds of the steps of a profile.
+const (
+	profileHook  = "hook"
+	profileTmux  = "tmux"
+	profileDelay = "delay"
+	profileWait  = "wait"
+)
+
+// profileStep is a step of starting sessions, and how long it took.
+type profileStep struct {
+	kind     string
+	name     string
+	duration time.Duration
+}
+
+// profile records where the time of a start went. Its methods do nothing on
+// a nil profile, when profiling is off.
+type profile struct {
+	mu    sync.Mutex
+	steps []profileStep
+}
+
+// add records a step.
+func (p *profile) add(kind, name string, start time.Time) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps = append(p.steps, profileStep{kind, name, time.Since(start)})
+}
+
+// report writes the total time of each kind of step, and the slowest steps.
+// Sessions start concurrently, so the totals may add up to more than the
+// time the start took.
+func (p *profile) report(w io.Writer, total time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(w, "Profile of %d steps in %s:\n", len(p.steps), total.Round(time.Millisecond))
+	for _, kind := range []string{profileHook, profileTmux, profileDelay, profileWait} {
+		count := 0
+		var sum time.Duration
+		for _, s := range p.steps {
+			if s.kind == kind {
+				count++
+				sum += s.duration
+			}
+		}
+		if count > 0 {
+			fmt.Fprintf(w, "  %-6s %5d %10s\n", kind, count, sum.Round(time.Millisecond))
+		}
+	}
+
+	slowest := slices.Clone(p.steps)
+	slices.SortStableFunc(slowest, func(a, b profileStep) int {
+		return cmp.Compare(b.duration, a.duration)
+	})
+	if len(slowest) > profileSlowest {
+		slowest = slowest[:profileSlowest]
+	}
+	if len(slowest) > 0 {
+		fmt.Fprintln(w, "Slowest:")
+	}
+	for _, s := range slowest {
+		fmt.Fprintf(w, "  %10s  %-6s %s\n", s.duration.Round(time.Millisecond), s.kind, s.name)
+	}
+}
+
+// wrap returns a commander which records the tmux commands it runs.
+func (p *profile) wrap(c shell.Commander) shell.Commander {
+	if p == nil {
+		return c
+	}
+	return profiledCommander{Commander: c, profile: p}
+}
+
+// profiledCommander records how long each command of another commander
+// took.
+type profiledCommander struct {
+	shell.Commander
+	profile *profile
+}
+
+func (c profiledCommander) Exec(cmd *exec.Cmd) (string, error) {
+	defer c.profile.add(profileTmux, commandName(cmd), time.Now())
+	return c.Commander.Exec(cmd)
+}
+
+func (c profiledCommander) ExecSilently(cmd *exec.Cmd) error {
+	defer c.profile.add(profileTmux, commandName(cmd), time.Now())
+	return c.Commander.ExecSilently(cmd)
+}
+
+func (c profiledCommander) ExecContext(ctx context.Context, cmd *exec.Cmd) (string, error) {
+	defer c.profile.add(profileTmux, commandName(cmd), time.Now())
+	return shell.ExecContext(ctx, c.Commander, cmd)
+}
+
+func (c profiledCommander) ExecSilentlyContext(ctx context.Context, cmd *exec.Cmd) error {
+	defer c.profile.add(profileTmux, commandName(cmd), time.Now())
+	return shell.ExecSilentlyContext(ctx, c.Commander, cmd)
+}
+
+// unwrapProfile returns the commander a profiled commander wraps.
+func unwrapProfile(c shell.Commander) shell.Commander {
+	if p, ok := c.(profiledCommander); ok {
+		return p.Commander
+	}
+	return c
+}
+
+// commandName names a command in a profile, by its program and arguments.
+func commandName(cmd *exec.Cmd) string {
+	args := slices.Clone(cmd.Args)
+	if len(args) > 0 {
+		args[0] = filepath.Base(args[0])
+	}
+	return strings.Join(args, " ")
+}
+
+// withProfile returns a client whose tmux commands are recorded by the
+// profile, if it's on.
+func (j Jig) withProfile() Jig {
+	if j.profile == nil {
+		return j
+	}
+	if client, ok := j.Tmux.(tmux.TmuxClient); ok {
+		if _, ok := client.Cmd.(profiledCommander); !ok {
+			client.Cmd = j.profile.wrap(client.Cmd)
+			j.Tmux = client
+		}
+	}
+	return j
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// rollback undoes a start which failed, so that starting again doesn't find
+// a partially created session. It kills the sessions and windows created by
+// the start, and runs the after commands of sessions whose before commands
+// ran, in the reverse order. A nil rollback records nothing.
+type rollback struct {
+	mu       sync.Mutex
+	steps    []func() error
+	sessions []string
+}
+
+// after records the after commands of a session whose before commands ran.
+// They run even if the start was interrupted.
+func (r *rollback) after(j Jig, session Config) {
+	if r == nil {
+		return
+	}
+	j.Context = context.WithoutCancel(j.ctx())
+	r.add(func() error {
+		err := j.execShellCommands(session.After, session.Path, session.AfterTimeout)
+		if err != nil {
+			return fmt.Errorf("after: %w", err)
+		}
+		return nil
+	})
+}
+
+// session records a session created by the start, which is killed with its
+// key bindings restored. The client which created the session kills it, as
+// a control mode connection is closed by then.
+func (r *rollback) session(j Jig, session Config) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.sessions = append(r.sessions, session.Session)
+	r.mu.Unlock()
+	r.add(func() error {
+		if err := j.unbindKeys(session); err != nil {
+			return err
+		}
+		_, err := j.Tmux.StopSession(tmux.Target{Session: session.Session})
+		return err
+	})
+}
+
+// window records a window created by the start in an existing session.
+// Windows of sessions created by the start are killed with them.
+func (r *rollback) window(client tmux.Client, target tmux.Target) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Contains(r.sessions, target.Session) {
+		return
+	}
+	r.steps = append(r.steps, func() error {
+		return client.KillWindow(target)
+	})
+}
+
+func (r *rollback) add(step func() error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+// run rolls back the start which failed with an error, and returns it with
+// the errors of rolling back.
+func (r *rollback) run(err error) error {
+	if r == nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	errs := []error{err}
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		if err := r.steps[i](); err != nil {
+			errs = append(errs, fmt.Errorf("rollback: %w", err))
+		}
+	}
+	r.steps = nil
+	return errors.Join(errs...)
+}
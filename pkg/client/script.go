@@ -0,0 +1,135 @@
+package client
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// Script returns a standalone POSIX shell script which recreates the
+// sessions using the same tmux commands that Start issues. Wait conditions
+// are not evaluated by the script.
+func (j Jig) Script(config Config, windows []string) (string, error) {
+	script := &shell.ScriptCommander{
+		// The script checks for existing sessions itself.
+		Skip: func(cmd *exec.Cmd) bool {
+			return slices.Contains(cmd.Args, "has-session")
+		},
+		// Capture identifiers printed by the -P flag, and queries.
+		Capture: func(cmd *exec.Cmd) bool {
+			return printsTarget(cmd.Args) || slices.Contains(cmd.Args, "display-message")
+		},
+	}
+	// The script assumes a tmux release which supports all features.
+	client, _ := j.Tmux.(tmux.TmuxClient)
+	client.Cmd = script
+	client.Versions = nil
+	if client.Bin == "" {
+		client.Bin = defaultTmuxCommand
+	}
+	j.Tmux = client
+	j.Cmd = script
+	j.script = script
+	j = j.WithConfig(config)
+	j.InSession = false
+
+	script.Println("#!/bin/sh")
+	if config.ConfigPath != "" {
+		script.Println(fmt.Sprintf("# Generated by jig from %s", config.ConfigPath))
+	}
+	script.Println("set -e")
+
+	order, err := sessionOrder(config.Sessions)
+	if err != nil {
+		return "", err
+	}
+	sessions := make([]Config, 0, len(order)+1)
+	for _, i := range order {
+		sessions = append(sessions, config.Sessions[i])
+	}
+	sessions = append(sessions, config)
+	for _, s := range sessions {
+		// The script does not wait between typed commands, nor for conditions.
+		s.CommandDelay = 0
+		s.Windows = slices.Clone(s.Windows)
+		for i := range s.Windows {
+			s.Windows[i].WaitFor = nil
+			s.Windows[i].Panes = withoutWaits(s.Windows[i].Panes)
+		}
+		script.Println("")
+		if !j.Options.Inside {
+			target := tmux.Target{Session: s.Session}
+			script.Println(fmt.Sprintf(
+				"if ! %s has-session -t %s 2>/dev/null; then",
+				scriptTmux(j.WithConfig(s)), shell.Quote(target.Get()),
+			))
+			script.Indent(1)
+		}
+		if err := j.startSession(s, windows); err != nil {
+			return "", err
+		}
+		if !j.Options.Inside {
+			script.Indent(-1)
+			script.Println("fi")
+		}
+	}
+
+	if !j.Options.Detach && !j.Options.Inside {
+		session := shell.Quote(config.Session)
+		script.Println("")
+		script.Println(`if [ -n "$TMUX" ]; then`)
+		script.Println("\t" + scriptTmux(j) + " switch-client -t " + session)
+		script.Println("else")
+		script.Println("\t" + scriptTmux(j) + " attach -d -t " + session)
+		script.Println("fi")
+	}
+	return script.String(), nil
+}
+
+// printsTarget returns true if tmux arguments create a session, window or
+// pane with the -P flag, which prints its identifier, in any of the commands
+// they chain.
+func printsTarget(args []string) bool {
+	creates := false
+	for _, arg := range args {
+		switch arg {
+		case ";":
+			creates = false
+		case "new-session", "new-window", "split-window":
+			creates = true
+		case "-P", "-Pd", "-PF":
+			if creates {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scriptTmux returns the tmux command of a client's server in a script, or
+// of the default server for other clients.
+func scriptTmux(j Jig) string {
+	client, _ := j.Tmux.(tmux.TmuxClient)
+	if client.Bin == "" {
+		client.Bin = defaultTmuxCommand
+	}
+	args := []string{client.Bin}
+	for _, arg := range client.ServerArgs() {
+		args = append(args, shell.Quote(arg))
+	}
+	return strings.Join(args, " ")
+}
+
+// withoutWaits returns a copy of panes without their wait conditions.
+func withoutWaits(panes []Pane) []Pane {
+	panes = slices.Clone(panes)
+	for i := range panes {
+		panes[i].WaitFor = nil
+		panes[i].Panes = withoutWaits(panes[i].Panes)
+	}
+	return panes
+}
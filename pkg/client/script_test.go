@@ -0,0 +1,65 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/tmux/tmuxtest"
+)
+
+func TestScript(t *testing.T) {
+	config := client.Config{
+		Session: "ses",
+		Path:    "/tmp",
+		Before:  []string{"echo 'up'"},
+		Windows: []client.Window{
+			{
+				Name: "win1",
+				Cmd:  "vim",
+				Panes: []client.Pane{
+					{Type: "horizontal", Cmd: "make watch"},
+				},
+			},
+			// A title which looks like the -P flag isn't captured.
+			{Name: "win2", Title: "-Pd"},
+		},
+	}
+
+	expected := `#!/bin/sh
+set -e
+
+if ! tmux has-session -t ses: 2>/dev/null; then
+	(cd /tmp && echo 'up')
+	jig_1=$(tmux new-session -Pd -F '#{session_id}' -s ses -n win1 -c /tmp)
+	tmux send-keys -t ses:win1 -l vim
+	tmux send-keys -t ses:win1 Enter
+	jig_2=$(tmux split-window -Pd -t ses:win1 -h -c /tmp -F '#{pane_id}')
+	tmux send-keys -t ses:win1."${jig_2}" -l 'make watch'
+	tmux send-keys -t ses:win1."${jig_2}" Enter
+	jig_3=$(tmux new-window -Pd -t ses: -n win2 -F '#{window_id}' -c /tmp)
+	tmux select-pane -t ses:"${jig_3}" -T -Pd
+fi
+
+if [ -n "$TMUX" ]; then
+	tmux switch-client -t ses
+else
+	tmux attach -d -t ses
+fi
+`
+
+	jig := client.Jig{}
+	script, err := jig.Script(config, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, script)
+}
+
+func TestScriptOtherClient(t *testing.T) {
+	// Clients which don't run tmux script the default tmux command.
+	jig := client.Jig{Tmux: tmuxtest.NewServer()}
+	script, err := jig.Script(client.Config{Session: "ses", Path: "/tmp"}, []string{})
+	assert.NoError(t, err)
+	assert.Contains(t, script, "if ! tmux has-session -t ses: 2>/dev/null; then\n")
+	assert.Contains(t, script, "\ttmux attach -d -t ses\n")
+}
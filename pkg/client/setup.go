@@ -0,0 +1,73 @@
+package client
+
+import "github.com/rafi/jig/pkg/tmux"
+
+// windowBuilder creates and sets up a window's panes, either running each
+// tmux command at once, like tmux.TmuxClient, or as a tmux.Batch.
+type windowBuilder interface {
+	NewPane(target tmux.Target, dir, split, size string, env map[string]string, command ...string) (string, error)
+	SelectPane(target tmux.Target) error
+	SetPaneTitle(target tmux.Target, title string) error
+	SelectLayout(target tmux.Target, layout string) (string, error)
+	SetOption(target tmux.Target, scope tmux.OptionScope, key, value string) error
+	SetHook(target tmux.Target, scope tmux.OptionScope, hook, command string) error
+	RespawnPane(target tmux.Target, env map[string]string, command string) error
+}
+
+// windowSetup sets up a window with a builder. Commands typed into panes of
+// a batch are pending until it ran, as the panes don't exist before.
+type windowSetup struct {
+	windowBuilder
+	batch   *tmux.Batch
+	pending []pendingCommands
+}
+
+// pendingCommands are commands to type into a pane.
+type pendingCommands struct {
+	target   tmux.Target
+	commands []string
+}
+
+// newWindowSetup returns the setup of a window, which is batched when tmux
+// commands are run as processes, and the window has no conditions to wait
+// for in between.
+func (j Jig) newWindowSetup(target tmux.Target, w Window) (*windowSetup, error) {
+	client, ok := runsTmux(j.Tmux)
+	if !ok || w.waits() {
+		return &windowSetup{windowBuilder: j.Tmux}, nil
+	}
+	batch, err := client.NewBatch(target)
+	if err != nil {
+		return nil, err
+	}
+	return &windowSetup{windowBuilder: batch, batch: batch}, nil
+}
+
+// typeCommands types commands into a pane, or once the setup's batch ran.
+func (j Jig) typeCommands(
+	session Config,
+	setup *windowSetup,
+	target tmux.Target,
+	commands []string,
+) {
+	switch {
+	case setup.batch == nil:
+		j.sendCommands(session, target, commands)
+	case len(commands) > 0:
+		setup.pending = append(setup.pending, pendingCommands{target, commands})
+	}
+}
+
+// runSetup runs the setup's batch, if any, and types its pending commands.
+func (j Jig) runSetup(session Config, setup *windowSetup) error {
+	if setup.batch == nil {
+		return nil
+	}
+	if err := setup.batch.Run(); err != nil {
+		return err
+	}
+	for _, p := range setup.pending {
+		j.sendCommands(session, setup.batch.Target(p.target), p.commands)
+	}
+	return nil
+}
@@ -2,28 +2,49 @@ package client
 
 import (
 	"fmt"
-	"path/filepath"
+	"maps"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rafi/jig/pkg/shell"
 	"github.com/rafi/jig/pkg/tmux"
 )
 
+// controlModeThreshold is the number of panes in a session from which its
+// commands are sent over a control mode connection.
+const controlModeThreshold = 10
+
 // Start starts a new tmux session, any nested sessions, run optional `before`
-// command and optionally attach to the first session.
+// command and optionally attach to the first session. Nested sessions are
+// started concurrently by their dependencies, before the main session. If a
+// session fails to start, the sessions and windows created so far are rolled
+// back, unless they're kept by the options.
 func (j Jig) Start(config Config, windows []string) error {
 	if j.Options.Inside && !j.InSession {
 		return ErrNotInsideSession
 	}
 
-	for _, s := range config.Sessions {
-		if err := j.startSession(s, windows); err != nil {
-			return err
-		}
+	j = j.WithConfig(config)
+	if !j.Options.KeepOnError {
+		j.undo = &rollback{}
 	}
-	if err := j.startSession(config, windows); err != nil {
-		return err
+	if j.Options.Profile {
+		j.profile = &profile{}
+		j = j.withProfile()
+	}
+	start := time.Now()
+	err := j.startSessions(config.Sessions, windows)
+	if err == nil {
+		err = j.startTimedSession(config, windows)
+	}
+	j.profile.report(j.stdout(), time.Since(start))
+	if err != nil {
+		return j.undo.run(err)
+	}
+	if j.Options.Timings && len(config.Sessions) > 0 {
+		fmt.Fprintf(j.stdout(), "Started %d sessions in %s\n", len(config.Sessions)+1, since(start))
 	}
 
 	// Attach/switch to the session.
@@ -34,8 +55,10 @@ func (j Jig) Start(config Config, windows []string) error {
 }
 
 // startSession starts a new tmux session, creates all windows and panes.
+// Errors are annotated with the step which failed.
 func (j Jig) startSession(session Config, windows []string) error {
 	var err error
+	j = j.WithConfig(session).withLog("session", session.Session)
 
 	// Use config session name, or current session name if windows should be
 	// created within the current session.
@@ -53,10 +76,25 @@ func (j Jig) startSession(session Config, windows []string) error {
 	if session.Path, err = session.GetSessionPath(); err != nil {
 		return err
 	}
+	if session.Windows, err = windowOrder(session.Windows); err != nil {
+		return err
+	}
+	if err := j.requireFeatures(session, windows); err != nil {
+		return err
+	}
 
+	// The first window is created with the session.
 	firstWinName := ""
+	firstWinPath := session.Path
+	var firstWinEnv map[string]string
+	var firstWinCommand []string
 	if len(session.Windows) > 0 {
 		firstWinName = session.Windows[0].Name
+		if !skipWindow(session.Windows[0], windows) {
+			firstWinPath = resolvePath(session.Windows[0].Path, session.Path)
+			firstWinEnv = session.Windows[0].Env
+			firstWinCommand = session.Windows[0].Command()
+		}
 	}
 
 	sessionExists := j.Tmux.SessionExists(sessionName)
@@ -64,138 +102,573 @@ func (j Jig) startSession(session Config, windows []string) error {
 	switch {
 	case j.Options.Inside:
 		// Skip session creation.
+		if err := j.prepareWorkdirs(session, windows); err != nil {
+			return err
+		}
 	case sessionExists && len(windows) == 0:
 		return nil
+	case sessionExists:
+		if err := j.prepareWorkdirs(session, windows); err != nil {
+			return err
+		}
 	case !sessionExists:
+		// Make missing directories before "before" commands run in the
+		// session's, and check them once the commands, which may make
+		// them too, ran.
+		if err := j.makeWorkdirs(session, windows); err != nil {
+			return err
+		}
+
 		// Execute "before" commands.
-		err := j.execShellCommands(session.Before, session.Path)
+		err := j.execShellCommands(session.Before, session.Path, session.BeforeTimeout)
 		if err != nil {
+			return fmt.Errorf("before: %w", err)
+		}
+		if len(session.Before) > 0 {
+			j.undo.after(j, session)
+		}
+		if err := j.checkWorkdirs(session, windows); err != nil {
 			return err
 		}
 
 		// Create new session and set environment variables.
-		_, err = j.Tmux.NewSession(session.Session, session.Path, firstWinName)
+		_, err = j.Tmux.NewSession(
+			session.Session, firstWinPath, firstWinName, firstWinCommand...)
 		if err != nil {
-			return err
+			return fmt.Errorf("create session: %w", err)
 		}
+		j.undo.session(j, session)
+		var closeControl func()
+		j, closeControl = j.withControlMode(session)
+		defer closeControl()
 		if len(session.Env) > 0 {
 			err = j.setEnvVariables(session.Session, session.Env)
 			if err != nil {
-				return err
+				return fmt.Errorf("set environment: %w", err)
 			}
 		}
+		// The -e flags of new-session would set the session's environment,
+		// so the first window's pane is respawned with its own instead.
+		if len(firstWinEnv) > 0 {
+			err := j.Tmux.RespawnPane(
+				tmux.Target{Session: session.Session}, firstWinEnv, strings.Join(firstWinCommand, " "))
+			if err != nil {
+				return fmt.Errorf("set environment: %w", err)
+			}
+		}
+		target := tmux.Target{Session: session.Session}
+		err = setOptions(j.Tmux, target, tmux.OptionScopeSession, session.Options)
+		if err != nil {
+			return fmt.Errorf("set options: %w", err)
+		}
+		if err := j.setHooks(session); err != nil {
+			return fmt.Errorf("set hooks: %w", err)
+		}
+		if err := j.bindKeys(session); err != nil {
+			return fmt.Errorf("bind keys: %w", err)
+		}
+	}
+	if err := j.createSessionWindows(session, windows); err != nil {
+		return err
+	}
+
+	// The first window was created before a custom base-index was set.
+	if _, ok := session.Options["base-index"]; ok && !sessionExists && !j.Options.Inside {
+		if err := j.Tmux.RenumberWindows(session.Session); err != nil {
+			return fmt.Errorf("renumber windows: %w", err)
+		}
 	}
-	return j.createSessionWindows(session, windows)
+	return nil
 }
 
 // createSessionWindows creates windows inside the session.
 func (j Jig) createSessionWindows(session Config, explicitWindows []string) error {
-	var err error
-	target := tmux.Target{Session: session.Session}
+	prev := tmux.Target{Session: session.Session}
 	for i, w := range session.Windows {
-		if (len(explicitWindows) == 0 && w.Manual) ||
-			(len(explicitWindows) > 0 && !slices.Contains(explicitWindows, w.Name)) {
+		if skipWindow(w, explicitWindows) {
 			continue
 		}
+		if err := j.ctx().Err(); err != nil {
+			return err
+		}
+		start := time.Now()
+		window := j.withLog("window", windowName(i, w))
+		target, err := window.createWindow(session, i, w, prev)
+		if err != nil {
+			return fmt.Errorf("%s: %w", windowStep(i, w), err)
+		}
+		window.log().Debug("created window", "duration", time.Since(start))
+		prev = target
+	}
+	return nil
+}
+
+// createWindow creates a window, unless it's the first one, which is created
+// with the session, and sets it up. The previously created pane is used to
+// evaluate the window's condition.
+func (j Jig) createWindow(session Config, i int, w Window, prev tmux.Target) (tmux.Target, error) {
+	var err error
+	target := tmux.Target{Session: session.Session}
+	if err := w.Validate(); err != nil {
+		return target, err
+	}
+	if err := checkLayout(w); err != nil {
+		return target, err
+	}
+
+	// Resolve window start directory.
+	w.Path = resolvePath(w.Path, session.Path)
+
+	// Create a window, unless it's the first one.
+	switch {
+	case i > 0 || j.Options.Inside:
+		target.Window, err = j.Tmux.NewWindow(target, w.Name, w.Path, w.Env, w.Command()...)
+		if err != nil {
+			return target, fmt.Errorf("create: %w", err)
+		}
+		j.undo.window(j.Tmux, target)
 
-		// Resolve window start directory.
-		if w.Path != "" {
-			w.Path = shell.ExpandPath(w.Path)
+	// If processing 1st window, and it's named - then use its name as id.
+	case i == 0 && w.Name != "":
+		target.Window = w.Name
+
+	// If first window is unnamed, ask tmux for the session's first window.
+	case i == 0 && w.Name == "":
+		currentWindows, err := j.Tmux.ListWindows(target)
+		if err != nil {
+			return target, err
 		}
-		if w.Path == "" || !filepath.IsAbs(w.Path) {
-			w.Path = filepath.Join(session.Path, w.Path)
+		if len(currentWindows) == 0 {
+			return target, ErrNoWindowsFound
 		}
+		target.Window = currentWindows[0].ID
+	}
 
-		// Create a window, unless it's the first one.
-		target.Window = ""
-		target.Pane = ""
-		switch {
-		case i > 0 || j.Options.Inside:
-			target.Window, err = j.Tmux.NewWindow(target, w.Name, w.Path)
-			if err != nil {
-				return err
-			}
+	// Optionally focus window.
+	if w.Focus {
+		if err := j.Tmux.SelectWindow(target); err != nil {
+			return target, fmt.Errorf("focus: %w", err)
+		}
+	}
 
-		// If processing 1st window, and it's named - then use its name as id.
-		case i == 0 && w.Name != "":
-			target.Window = w.Name
+	setup, err := j.newWindowSetup(target, w)
+	if err != nil {
+		return target, err
+	}
+	if err := j.startProcess(setup, target, prev, w.Path, w.Process); err != nil {
+		return target, fmt.Errorf("start process: %w", err)
+	}
 
-		// If first window is unnamed, ask tmux for the session's first window.
-		case i == 0 && w.Name == "":
-			currentWindows, err := j.Tmux.ListWindows(target)
-			if err != nil {
-				return err
-			}
-			if len(currentWindows) == 0 {
-				return ErrNoWindowsFound
-			}
-			target.Window = currentWindows[0].ID
+	if w.Title != "" {
+		if err := setup.SetPaneTitle(target, w.Title); err != nil {
+			return target, fmt.Errorf("set title: %w", err)
 		}
+	}
 
-		// Optionally focus window.
-		if w.Focus {
-			err := j.Tmux.SelectWindow(target)
-			if err != nil {
-				return err
-			}
+	// Run window commands.
+	j.typeCommands(session, setup, target, w.GetCommands())
+
+	// Create panes.
+	err = j.createPanes(session, setup, target, w.Path, w.Split, inheritEnv(w.Panes, w.Env))
+	if err != nil {
+		return target, err
+	}
+
+	if w.Layout != "" {
+		if err := j.selectLayout(setup, target, w); err != nil {
+			return target, fmt.Errorf("select layout: %w", err)
 		}
+	}
 
-		// Run window commands.
-		newWinCommands := w.GetCommands()
-		for _, cmd := range newWinCommands {
-			if session.SuppressHistory {
-				cmd = " " + cmd
-			}
-			time.Sleep(time.Millisecond * time.Duration(session.CommandDelay))
-			err := j.Tmux.SendKeys(target, cmd)
-			if err != nil {
-				fmt.Println(err)
+	// Set window options last, e.g. synchronize-panes would otherwise
+	// duplicate commands typed into panes.
+	options := map[string]string{}
+	if w.PaneTitles == "" {
+		w.PaneTitles = session.PaneTitles
+	}
+	if w.PaneTitles != "" {
+		options["pane-border-status"] = w.PaneTitles
+		options["pane-border-format"] = " #{pane_title} "
+	}
+	maps.Copy(options, session.WindowOptions)
+	maps.Copy(options, w.Options)
+	if len(setup.pending) == 0 {
+		err = setOptions(setup, target, tmux.OptionScopeWindow, options)
+		if err != nil {
+			return target, fmt.Errorf("set options: %w", err)
+		}
+		options = nil
+	}
+	if err := j.runSetup(session, setup); err != nil {
+		return target, fmt.Errorf("set up: %w", err)
+	}
+	err = setOptions(j.Tmux, target, tmux.OptionScopeWindow, options)
+	if err != nil {
+		return target, fmt.Errorf("set options: %w", err)
+	}
+	return target, nil
+}
+
+// windowStep describes a window in errors, by its name or position.
+func windowStep(i int, w Window) string {
+	if w.Name != "" {
+		return fmt.Sprintf("window %q", w.Name)
+	}
+	return fmt.Sprintf("window %d", i+1)
+}
+
+// windowName names a window in logs, by its name or position.
+func windowName(i int, w Window) string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// withControlMode returns a client which runs the session's commands over a
+// single control mode connection, if the session is large enough to benefit
+// from it, and a function to close the connection. Commanders which don't
+// execute commands, e.g. of scripts, are kept.
+func (j Jig) withControlMode(session Config) (Jig, func()) {
+	client, ok := runsTmux(j.Tmux)
+	if !ok || j.Options.NoControl || countPanes(session) < controlModeThreshold {
+		return j, func() {}
+	}
+	// The commands the control mode connection falls back to are recorded by
+	// the profile as part of the connection's.
+	client.Cmd = unwrapProfile(client.Cmd)
+	control, err := tmux.NewControlCommander(client, session.Session)
+	if err != nil {
+		// Fall back to a tmux process per command.
+		return j, func() {}
+	}
+	client.Cmd = j.profile.wrap(control)
+	j.Tmux = client
+	return j, func() { _ = control.Close() }
+}
+
+// requireFeatures returns an error if tmux doesn't support a feature the
+// processes of the session's windows, or its hooks and bindings need, before
+// the session is created.
+func (j Jig) requireFeatures(session Config, explicitWindows []string) error {
+	for _, commands := range []map[string]string{session.Hooks, session.Bindings} {
+		for _, command := range commands {
+			if usesPopup(tmux.SplitCommand(command)) {
+				if err := j.Tmux.Require(tmux.FeaturePopup); err != nil {
+					return err
+				}
 			}
 		}
+	}
 
-		// Create panes.
-		for _, p := range w.Panes {
-			// Resolve pane start directory.
-			if p.Path != "" {
-				p.Path = shell.ExpandPath(p.Path)
+	var require func(p Process) error
+	require = func(p Process) error {
+		if len(p.Env) > 0 {
+			if err := j.Tmux.Require(tmux.FeatureEnv); err != nil {
+				return err
 			}
-			panePath := shell.ExpandPath(p.Path)
-			if p.Path == "" || !filepath.IsAbs(panePath) {
-				panePath = filepath.Join(w.Path, p.Path)
+		}
+		if p.Supervised() {
+			if err := j.Tmux.Require(tmux.FeaturePaneOptions); err != nil {
+				return err
 			}
-
-			target.Pane, err = j.Tmux.NewPane(target, panePath, p.Type)
-			if err != nil {
+		}
+		if p.Restart != "" {
+			return j.Tmux.Require(tmux.FeaturePaneHooks)
+		}
+		return nil
+	}
+	var requirePanes func(panes []Pane) error
+	requirePanes = func(panes []Pane) error {
+		for _, p := range panes {
+			if err := require(p.Process); err != nil {
+				return err
+			}
+			if err := requirePanes(p.Panes); err != nil {
 				return err
 			}
+		}
+		return nil
+	}
+	for _, w := range session.Windows {
+		if skipWindow(w, explicitWindows) {
+			continue
+		}
+		if err := require(w.Process); err != nil {
+			return err
+		}
+		if err := requirePanes(w.Panes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			// Run commands inside pane.
-			for _, cmd := range p.GetCommands() {
-				if session.SuppressHistory {
-					cmd = " " + cmd
-				}
-				time.Sleep(time.Millisecond * time.Duration(session.CommandDelay))
-				err := j.Tmux.SendKeys(target, cmd)
-				if err != nil {
-					fmt.Println(err)
-				}
+// usesPopup returns true if a command, as split by tmux.SplitCommand, or a
+// command given as its argument, e.g. of if-shell, opens a popup.
+func usesPopup(args []string) bool {
+	command := true
+	for _, arg := range args {
+		switch {
+		case command && (arg == "display-popup" || arg == "popup"):
+			return true
+		case strings.ContainsAny(arg, " \t") && usesPopup(tmux.SplitCommand(arg)):
+			return true
+		}
+		command = arg == `\;`
+	}
+	return false
+}
+
+// runsTmux returns the client if it runs tmux commands as processes.
+func runsTmux(c tmux.Client) (tmux.TmuxClient, bool) {
+	client, ok := c.(tmux.TmuxClient)
+	if !ok {
+		return client, false
+	}
+	_, ok = unwrapProfile(client.Cmd).(shell.DefaultCommander)
+	return client, ok
+}
+
+// countPanes returns the number of panes in a session.
+func countPanes(session Config) int {
+	n := 0
+	for _, w := range session.Windows {
+		n += 1 + countNestedPanes(w.Panes)
+	}
+	return n
+}
+
+// countNestedPanes returns the number of panes, including nested ones.
+func countNestedPanes(panes []Pane) int {
+	n := len(panes)
+	for _, p := range panes {
+		n += countNestedPanes(p.Panes)
+	}
+	return n
+}
+
+// resolvePath resolves a window or pane directory, relative to its parent's
+// directory.
+func resolvePath(path, parent string) string {
+	if path == "" {
+		return parent
+	}
+	return shell.ResolvePath(path, parent)
+}
+
+// inheritEnv returns panes whose environment variables, and those of their
+// nested panes, default to their parent's.
+func inheritEnv(panes []Pane, env map[string]string) []Pane {
+	inherited := make([]Pane, len(panes))
+	for i, p := range panes {
+		if len(env) > 0 {
+			merged := maps.Clone(env)
+			maps.Copy(merged, p.Env)
+			p.Env = merged
+		}
+		p.Panes = inheritEnv(p.Panes, p.Env)
+		inherited[i] = p
+	}
+	return inherited
+}
+
+// skipWindow returns true if a window shouldn't be created, either as it's
+// manual, or not one of the explicitly requested windows.
+func skipWindow(w Window, explicitWindows []string) bool {
+	if len(explicitWindows) == 0 {
+		return w.Manual
+	}
+	return !slices.Contains(explicitWindows, w.Name)
+}
+
+// createPanes creates panes by splitting the parent pane, each one splitting
+// the previously created sibling. Nested panes are created after all
+// siblings exist, so that they divide their parent's final area.
+func (j Jig) createPanes(
+	session Config,
+	setup *windowSetup,
+	parent tmux.Target,
+	parentPath, split string,
+	panes []Pane,
+) error {
+	var err error
+	targets := make([]tmux.Target, len(panes))
+	paths := make([]string, len(panes))
+	target := parent
+	for i, p := range panes {
+		// Resolve pane start directory.
+		panePath := resolvePath(p.Path, parentPath)
+
+		splitType := p.Type
+		if splitType == "" {
+			splitType = split
+		}
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("pane %d: %w", i+1, err)
+		}
+		if err := j.ctx().Err(); err != nil {
+			return err
+		}
+		prev := target
+		target.Pane, err = setup.NewPane(
+			target, panePath, splitType, p.Size, p.Env, p.Command()...)
+		if err != nil {
+			return fmt.Errorf("pane %d: create: %w", i+1, err)
+		}
+		if err := j.startProcess(setup, target, prev, panePath, p.Process); err != nil {
+			return fmt.Errorf("pane %d: start process: %w", i+1, err)
+		}
+		if p.Title != "" {
+			if err := setup.SetPaneTitle(target, p.Title); err != nil {
+				return fmt.Errorf("pane %d: set title: %w", i+1, err)
 			}
+		}
 
-			// Optionally focus a pane.
-			if p.Focus {
-				if err := j.Tmux.SelectPane(target); err != nil {
-					return err
-				}
+		// Run commands inside pane.
+		j.typeCommands(session, setup, target, p.GetCommands())
+
+		// Optionally focus a pane.
+		if p.Focus {
+			if err := setup.SelectPane(target); err != nil {
+				return fmt.Errorf("pane %d: focus: %w", i+1, err)
 			}
 		}
+		targets[i] = target
+		paths[i] = panePath
+	}
 
-		target.Pane = ""
-		if w.Layout != "" {
-			_, err := j.Tmux.SelectLayout(target, w.Layout)
+	for i, p := range panes {
+		if len(p.Panes) == 0 {
+			continue
+		}
+		err := j.createPanes(session, setup, targets[i], paths[i], p.Split, p.Panes)
+		if err != nil {
+			return fmt.Errorf("pane %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// checkLayout returns an error if a window's custom layout has fewer panes
+// than the window, before the window is created.
+func checkLayout(w Window) error {
+	if !tmux.IsCustomLayout(w.Layout) {
+		return nil
+	}
+	layout, err := tmux.ParseLayout(w.Layout)
+	if err != nil {
+		return err
+	}
+	if panes := 1 + countNestedPanes(w.Panes); layout.PaneCount() < panes {
+		return fmt.Errorf("%w: layout has %d panes, window has %d",
+			tmux.ErrInvalidLayout, layout.PaneCount(), panes)
+	}
+	return nil
+}
+
+// selectLayout applies a window's layout. Custom layouts are scaled to the
+// window's current size, and when the window has fewer panes than the
+// layout, the missing panes are created to match it.
+func (j Jig) selectLayout(setup *windowSetup, target tmux.Target, w Window) error {
+	if !tmux.IsCustomLayout(w.Layout) {
+		_, err := setup.SelectLayout(target, w.Layout)
+		return err
+	}
+	layout, err := tmux.ParseLayout(w.Layout)
+	if err != nil {
+		return err
+	}
+
+	for i := 1 + countNestedPanes(w.Panes); i < layout.PaneCount(); i++ {
+		if _, err := setup.NewPane(target, w.Path, "vertical", "", w.Env); err != nil {
+			return err
+		}
+		// Even out panes, so the next split has enough room.
+		if _, err := setup.SelectLayout(target, tmux.LayoutTiled); err != nil {
+			return err
+		}
+	}
+
+	size, err := j.Tmux.WindowSize(target)
+	if err != nil {
+		return err
+	}
+	if err := layout.Resize(size.Width, size.Height); err != nil {
+		return err
+	}
+	_, err = setup.SelectLayout(target, layout.String())
+	return err
+}
+
+// restartDelay is the number of seconds a process waits before it restarts.
+const restartDelay = 1
+
+// startProcess waits for a pane's condition, keeps the pane on exit and sets
+// its restart policy, then respawns the pane with its process. The previous
+// pane and directory are used to evaluate the condition.
+func (j Jig) startProcess(
+	b windowBuilder,
+	target, prev tmux.Target,
+	dir string,
+	p Process,
+) error {
+	if p.WaitFor != nil {
+		if err := j.waitFor(*p.WaitFor, prev, dir); err != nil {
+			return err
+		}
+	}
+
+	if p.Supervised() {
+		err := b.SetOption(target, tmux.OptionScopePane, "remain-on-exit", "on")
+		if err != nil {
+			return err
+		}
+
+		// Delay the respawn, so a process that fails right away doesn't
+		// restart in a tight loop. tmux doesn't keep the pane's environment
+		// variables on respawn.
+		respawn := tmux.JoinCommand(append(
+			[]string{"run-shell", "-d", strconv.Itoa(restartDelay), `\;`, "respawn-pane"},
+			tmux.EnvArgs(p.Env)...))
+		hook := ""
+		switch p.Restart {
+		case RestartAlways:
+			hook = respawn
+		case RestartOnFailure:
+			hook = tmux.JoinCommand([]string{"if-shell", "-F", "#{!=:#{pane_dead_status},0}", respawn})
+		}
+		if hook != "" {
+			err := b.SetHook(target, tmux.OptionScopePane, "pane-died", hook)
 			if err != nil {
 				return err
 			}
 		}
 	}
-	return nil
+
+	if p.Run == "" || len(p.Command()) > 0 {
+		return nil
+	}
+	return b.RespawnPane(target, p.Env, p.Run)
+}
+
+// sendCommands types commands into a pane, waiting between each one, until
+// the client's context is done.
+func (j Jig) sendCommands(session Config, target tmux.Target, commands []string) {
+	for _, cmd := range commands {
+		if session.SuppressHistory {
+			cmd = " " + cmd
+		}
+		start := time.Now()
+		select {
+		case <-j.ctx().Done():
+			return
+		case <-time.After(time.Millisecond * time.Duration(session.CommandDelay)):
+		}
+		j.profile.add(profileDelay, target.Get(), start)
+		err := j.Tmux.SendKeys(target, cmd)
+		if err != nil {
+			fmt.Fprintln(j.stdout(), err)
+		}
+	}
 }
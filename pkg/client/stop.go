@@ -1,19 +1,33 @@
 package client
 
-import "github.com/rafi/jig/pkg/tmux"
+import (
+	"errors"
 
-// Stop stops a tmux session and its nested sessions, if any.
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// Stop stops a tmux session and its nested sessions, if any, in the reverse
+// order of starting them.
 func (j Jig) Stop(config Config, windows []string) error {
-	for _, s := range config.Sessions {
-		if err := j.stopSession(s, windows); err != nil {
+	j = j.WithConfig(config)
+	order, err := sessionOrder(config.Sessions)
+	if err != nil {
+		return err
+	}
+	if err := j.stopSession(config, windows); err != nil {
+		return err
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		if err := j.stopSession(config.Sessions[order[i]], windows); err != nil {
 			return err
 		}
 	}
-	return j.stopSession(config, windows)
+	return nil
 }
 
 // stopSession stops a tmux session, and optionally run `after` commands.
 func (j Jig) stopSession(session Config, windows []string) error {
+	j = j.WithConfig(session).withLog("session", session.Session)
 	target := tmux.Target{Session: session.Session}
 
 	if len(windows) == 0 {
@@ -23,12 +37,20 @@ func (j Jig) stopSession(session Config, windows []string) error {
 			if err != nil {
 				return err
 			}
-			if err := j.execShellCommands(session.After, sessionPath); err != nil {
+			if err := j.execShellCommands(session.After, sessionPath, session.AfterTimeout); err != nil {
 				return err
 			}
 		}
-		_, err := j.Tmux.StopSession(target)
-		return err
+		// Kill the session even if its bindings can't be restored.
+		unbindErr := j.unbindKeys(session)
+		if _, err := j.Tmux.StopSession(target); err != nil {
+			return errors.Join(unbindErr, err)
+		}
+		if unbindErr != nil {
+			return unbindErr
+		}
+		j.log().Info("stopped session")
+		return nil
 	}
 
 	// Kill specific windows
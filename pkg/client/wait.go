@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+const (
+	defaultWaitTimeout  = 30 * time.Second
+	defaultWaitInterval = 500 * time.Millisecond
+)
+
+// WaitFor is a condition which is checked repeatedly until it's met, or
+// until it times out. Exactly one of TCP, File, Command or Output is set.
+type WaitFor struct {
+	// TCP is an address which accepts connections, e.g. "localhost:5432".
+	TCP string `yaml:"tcp,omitempty"`
+
+	// File is a path which exists, relative to the pane's directory.
+	File string `yaml:"file,omitempty"`
+
+	// Command is a shell command which exits successfully, run in the pane's
+	// directory.
+	Command string `yaml:"command,omitempty"`
+
+	// Output is text which appears in a pane, or a regular expression if
+	// wrapped in slashes, e.g. "/Listening on :\d+/".
+	Output string `yaml:"output,omitempty"`
+
+	// Pane is the pane whose output is matched, relative to the session, e.g.
+	// "db" or "db.1". Defaults to the previously created pane.
+	Pane string `yaml:"pane,omitempty"`
+
+	Timeout  time.Duration `yaml:"timeout,omitempty"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// Validate checks that a single condition is set and that an output pattern
+// is a valid regular expression.
+func (w WaitFor) Validate() error {
+	conditions := 0
+	for _, c := range []string{w.TCP, w.File, w.Command, w.Output} {
+		if c != "" {
+			conditions++
+		}
+	}
+	if conditions != 1 {
+		return fmt.Errorf("%w: expected one of tcp, file, command or output", ErrInvalidWait)
+	}
+	if _, err := outputPattern(w.Output); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidWait, err)
+	}
+	return nil
+}
+
+// String returns a description of the condition.
+func (w WaitFor) String() string {
+	switch {
+	case w.TCP != "":
+		return "tcp " + w.TCP
+	case w.File != "":
+		return "file " + w.File
+	case w.Command != "":
+		return "command " + w.Command
+	default:
+		return "output " + w.Output
+	}
+}
+
+// waitFor blocks until a condition is met, or the client's context is done.
+// Paths and commands are relative to dir, and output is matched against
+// prev, the previously created pane.
+func (j Jig) waitFor(w WaitFor, prev tmux.Target, dir string) error {
+	timeout := w.Timeout
+	if timeout == 0 {
+		timeout = defaultWaitTimeout
+	}
+	interval := w.Interval
+	if interval == 0 {
+		interval = defaultWaitInterval
+	}
+
+	check, err := j.waitCondition(w, prev, dir, interval)
+	if err != nil {
+		return err
+	}
+	defer j.profile.add(profileWait, w.String(), time.Now())
+	deadline := time.Now().Add(timeout)
+	// Checks are stopped at the deadline, e.g. a command which hangs.
+	ctx, cancel := context.WithDeadline(j.ctx(), deadline)
+	defer cancel()
+	for {
+		// Only the last failure is logged, as a condition is expected to
+		// fail until it's met.
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		if err := j.ctx().Err(); err != nil {
+			return err
+		}
+		if !time.Now().Before(deadline) {
+			j.log().Warn("wait timed out", "wait", w.String(), "err", err)
+			return fmt.Errorf("%w %s after %s", ErrWaitTimeout, w, timeout)
+		}
+		select {
+		case <-j.ctx().Done():
+			return j.ctx().Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// waitCondition returns a function which checks whether a condition is met
+// before its context is done, and otherwise returns why not.
+func (j Jig) waitCondition(
+	w WaitFor,
+	prev tmux.Target,
+	dir string,
+	interval time.Duration,
+) (func(ctx context.Context) error, error) {
+	switch {
+	case w.TCP != "":
+		return func(ctx context.Context) error {
+			dialer := net.Dialer{Timeout: interval}
+			conn, err := dialer.DialContext(ctx, "tcp", w.TCP)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}, nil
+
+	case w.File != "":
+		path := resolvePath(w.File, dir)
+		return func(context.Context) error {
+			_, err := os.Stat(path)
+			return err
+		}, nil
+
+	case w.Command != "":
+		// Failed probes aren't logged by the commander either.
+		commander := j.commander()
+		if c, ok := commander.(shell.DefaultCommander); ok {
+			c.Logger = nil
+			commander = c
+		}
+		return func(ctx context.Context) error {
+			cmd := exec.Command("/bin/sh", "-c", w.Command)
+			cmd.Dir = shell.ExpandPath(dir)
+			return shell.ExecSilentlyContext(ctx, commander, cmd)
+		}, nil
+	}
+
+	pattern, err := outputPattern(w.Output)
+	if err != nil {
+		return nil, err
+	}
+	target := prev
+	if w.Pane != "" {
+		target = paneTarget(prev.Session, w.Pane)
+	}
+	if target.Window == "" && target.Pane == "" {
+		return nil, fmt.Errorf("%w: no previous pane to match output in", ErrInvalidWait)
+	}
+	return func(context.Context) error {
+		out, err := j.Tmux.CapturePane(target)
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(out) {
+			return fmt.Errorf("no match in pane %s", target.Get())
+		}
+		return nil
+	}, nil
+}
+
+// paneTarget returns the target of a pane relative to a session, e.g. "db"
+// is the active pane of window db, and "db.1" its pane with index 1.
+func paneTarget(session, pane string) tmux.Target {
+	window, index, _ := strings.Cut(pane, ".")
+	return tmux.Target{Session: session, Window: window, Pane: index}
+}
+
+// outputPattern compiles an output pattern, which is a regular expression
+// if wrapped in slashes, or otherwise a literal text.
+func outputPattern(s string) (*regexp.Regexp, error) {
+	if len(s) > 1 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/") {
+		return regexp.Compile(s[1 : len(s)-1])
+	}
+	return regexp.Compile(regexp.QuoteMeta(s))
+}
+
+// waits returns true if a window or any of its panes waits for a condition.
+func (w Window) waits() bool {
+	var panesWait func(panes []Pane) bool
+	panesWait = func(panes []Pane) bool {
+		for _, p := range panes {
+			if p.WaitFor != nil || panesWait(p.Panes) {
+				return true
+			}
+		}
+		return false
+	}
+	return w.WaitFor != nil || panesWait(w.Panes)
+}
@@ -0,0 +1,193 @@
+package client_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+	"github.com/rafi/jig/pkg/tmux/tmuxtest"
+)
+
+func TestWaitFor(t *testing.T) {
+	start := func(config client.Config, outputs ...string) (*MockCommander, error) {
+		commander := &MockCommander{[]string{}, outputs}
+		jig := client.Jig{
+			Tmux:    tmux.TmuxClient{Bin: "tmux", Cmd: commander},
+			Cmd:     commander,
+			Options: client.Options{Detach: true},
+		}
+		return commander, jig.Start(config, []string{})
+	}
+
+	t.Run("tcp, file and command", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "ready"), nil, 0o600))
+
+		commander, err := start(client.Config{
+			Session: "ses",
+			Path:    dir,
+			Windows: []client.Window{
+				{
+					Name: "win1",
+					Panes: []client.Pane{
+						{
+							Type: "horizontal",
+							Cmd:  "psql",
+							Process: client.Process{
+								WaitFor: &client.WaitFor{TCP: listener.Addr().String()},
+							},
+						},
+						{
+							Type: "vertical",
+							Process: client.Process{
+								Run:     "make serve",
+								WaitFor: &client.WaitFor{File: "ready"},
+							},
+						},
+					},
+				},
+				{
+					Name: "win2",
+					Process: client.Process{
+						WaitFor: &client.WaitFor{Command: "pg_isready"},
+					},
+				},
+			},
+		}, "ses", "$1", "%1", "%2", "@3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"tmux has-session -t ses:",
+			"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c " + dir,
+			"tmux split-window -Pd -t ses:win1 -h -c " + dir + " -F #{pane_id}",
+			"tmux send-keys -t ses:win1.%1 -l psql",
+			"tmux send-keys -t ses:win1.%1 Enter",
+			"tmux split-window -Pd -t ses:win1.%1 -v -c " + dir + " -F #{pane_id}",
+			"tmux respawn-pane -k -t ses:win1.%2 make serve",
+			"tmux new-window -Pd -t ses: -n win2 -F #{window_id} -c " + dir,
+			"/bin/sh -c pg_isready",
+		}, commander.Commands)
+	})
+
+	t.Run("output of previous pane", func(t *testing.T) {
+		commander, err := start(client.Config{
+			Session: "ses",
+			Path:    "/tmp",
+			Windows: []client.Window{
+				{
+					Name: "win1",
+					Panes: []client.Pane{
+						{
+							Type: "horizontal",
+							Cmd:  "curl localhost:8080",
+							Process: client.Process{
+								WaitFor: &client.WaitFor{Output: `/Listening on :\d+/`},
+							},
+						},
+					},
+				},
+			},
+		}, "ses", "$1", "%1", "Listening on :8080")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"tmux has-session -t ses:",
+			"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+			"tmux split-window -Pd -t ses:win1 -h -c /tmp -F #{pane_id}",
+			"tmux capture-pane -p -J -S - -t ses:win1",
+			"tmux send-keys -t ses:win1.%1 -l curl localhost:8080",
+			"tmux send-keys -t ses:win1.%1 Enter",
+		}, commander.Commands)
+	})
+
+	t.Run("output of another pane", func(t *testing.T) {
+		commander, err := start(client.Config{
+			Session: "ses",
+			Path:    "/tmp",
+			Windows: []client.Window{
+				{
+					Name: "win1",
+					Process: client.Process{
+						Run:     "make migrate",
+						WaitFor: &client.WaitFor{Output: "ready", Pane: "db.1"},
+					},
+				},
+			},
+		}, "ses", "$1", "database ready", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"tmux has-session -t ses:",
+			"tmux new-session -Pd -F #{session_id} -s ses -n win1 -c /tmp",
+			"tmux capture-pane -p -J -S - -t ses:db.1",
+			"tmux respawn-pane -k -t ses:win1 make migrate",
+		}, commander.Commands)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+
+		_, err = start(client.Config{
+			Session: "ses",
+			Path:    "/tmp",
+			Windows: []client.Window{
+				{
+					Name: "win1",
+					Process: client.Process{
+						WaitFor: &client.WaitFor{
+							TCP:      addr,
+							Timeout:  50 * time.Millisecond,
+							Interval: 10 * time.Millisecond,
+						},
+					},
+				},
+			},
+		}, "ses", "$1")
+		assert.ErrorIs(t, err, client.ErrWaitTimeout)
+	})
+
+	t.Run("hanging command", func(t *testing.T) {
+		jig := client.Jig{
+			Tmux:    tmuxtest.NewServer(),
+			Cmd:     shell.DefaultCommander{},
+			Options: client.Options{Detach: true},
+		}
+		begin := time.Now()
+		err := jig.Start(client.Config{
+			Session: "ses",
+			Path:    t.TempDir(),
+			Windows: []client.Window{
+				{
+					Name: "win1",
+					Process: client.Process{
+						WaitFor: &client.WaitFor{Command: "sleep 5", Timeout: 100 * time.Millisecond},
+					},
+				},
+			},
+		}, []string{})
+		assert.ErrorIs(t, err, client.ErrWaitTimeout)
+		assert.Less(t, time.Since(begin), 2*time.Second)
+	})
+
+	t.Run("invalid conditions", func(t *testing.T) {
+		for _, wait := range []client.WaitFor{
+			{},
+			{TCP: "localhost:5432", File: "ready"},
+			{Output: "/(/"},
+		} {
+			assert.ErrorIs(t, wait.Validate(), client.ErrInvalidWait, wait)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+// Package convert translates session configurations between jig and other
+// tmux session managers.
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rafi/jig/pkg/client"
+)
+
+type Format string
+
+const (
+	FormatTmuxinator Format = "tmuxinator"
+	FormatTmuxp      Format = "tmuxp"
+	FormatSmug       Format = "smug"
+)
+
+var ErrUnknownFormat = errors.New("unknown config format")
+
+// Import parses a foreign config and converts it into a jig config. It also
+// returns a sorted list of keys that have no jig equivalent and were dropped.
+func Import(format Format, data []byte) (client.Config, []string, error) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return client.Config{}, nil, err
+	}
+
+	var imp importer
+	switch format {
+	case FormatTmuxinator:
+		imp.tmuxinator(raw)
+	case FormatTmuxp:
+		imp.tmuxp(raw)
+	case FormatSmug:
+		imp.smug(raw)
+	default:
+		return client.Config{}, nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	sort.Strings(imp.dropped)
+	return imp.config, imp.dropped, nil
+}
+
+// importer accumulates a converted config and the keys it had to drop.
+type importer struct {
+	config  client.Config
+	dropped []string
+}
+
+// drop records all keys of a mapping which are not listed as known.
+func (i *importer) drop(prefix string, m map[string]any, known ...string) {
+	for key := range m {
+		isKnown := false
+		for _, k := range known {
+			if key == k {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			i.dropped = append(i.dropped, prefix+key)
+		}
+	}
+}
+
+// toString returns a scalar value as a string, or empty for anything else.
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool, int, float64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// toStrings returns a scalar or a sequence of scalars as a list of strings.
+func toStrings(v any) []string {
+	list, ok := v.([]any)
+	if !ok {
+		if s := toString(v); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	result := []string{}
+	for _, item := range list {
+		if s := toString(item); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// toEnv returns a mapping of scalars as environment variables.
+func toEnv(v any) map[string]string {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	env := make(map[string]string, len(m))
+	for key, value := range m {
+		env[key] = toString(value)
+	}
+	return env
+}
+
+// toBool returns a boolean value, or false for anything else.
+func toBool(v any) bool {
+	b, ok := v.(bool)
+	return ok && b
+}
+
+// sortedKeys returns the keys of a mapping in order, so that conversions
+// don't depend on the order of map iteration.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitPanes converts a list of panes, where the first one belongs to the
+// window itself, into window commands and extra split panes. The first
+// pane's focus has no window equivalent, and is dropped.
+func (i *importer) splitPanes(prefix string, w *client.Window, panes []client.Pane) {
+	if len(panes) == 0 {
+		return
+	}
+	first := panes[0]
+	w.Commands = append(w.Commands, first.Commands...)
+	if first.Path != "" && w.Path == "" {
+		w.Path = first.Path
+	}
+	if first.Title != "" && w.Title == "" {
+		w.Title = first.Title
+	}
+	if first.Focus {
+		i.dropped = append(i.dropped, prefix+"panes[0].focus")
+	}
+	for _, p := range panes[1:] {
+		if p.Type == "" {
+			p.Type = "vertical"
+		}
+		w.Panes = append(w.Panes, p)
+	}
+}
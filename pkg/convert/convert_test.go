@@ -0,0 +1,296 @@
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/pkg/client"
+	"github.com/rafi/jig/pkg/convert"
+)
+
+func TestImport(t *testing.T) {
+	testTable := map[string]struct {
+		format   convert.Format
+		input    string
+		expected client.Config
+		dropped  []string
+	}{
+		"tmuxinator": {
+			convert.FormatTmuxinator,
+			`
+name: blog
+root: ~/code/blog
+on_project_start: docker compose up -d
+on_project_stop: docker compose stop
+pre_window: nvm use
+startup_window: logs
+windows:
+  - editor:
+      layout: main-vertical
+      synchronize: after
+      panes:
+        - vim
+        - [make watch, echo done]
+  - logs: tail -f log/dev.log
+`,
+			client.Config{
+				Session: "blog",
+				Path:    "~/code/blog",
+				Before:  []string{"docker compose up -d"},
+				After:   []string{"docker compose stop"},
+				Windows: []client.Window{
+					{
+						Name:     "editor",
+						Layout:   "main-vertical",
+						Commands: []string{"nvm use", "vim"},
+						Panes: []client.Pane{
+							{
+								Type:     "vertical",
+								Commands: []string{"nvm use", "make watch", "echo done"},
+							},
+						},
+					},
+					{
+						Name:     "logs",
+						Commands: []string{"nvm use", "tail -f log/dev.log"},
+					},
+				},
+			},
+			[]string{"startup_window", "windows[0].editor.synchronize"},
+		},
+		"tmuxinator named panes": {
+			convert.FormatTmuxinator,
+			`
+name: blog
+windows:
+  - server: rails s
+    console: rails c
+  - editor:
+      panes:
+        - code: vim
+        - tests:
+            - make test
+          logs: tail -f log
+`,
+			client.Config{
+				Session: "blog",
+				Windows: []client.Window{
+					{Name: "console", Commands: []string{"rails c"}},
+					{Name: "server", Commands: []string{"rails s"}},
+					{
+						Name:     "editor",
+						Title:    "code",
+						Commands: []string{"vim"},
+						Panes: []client.Pane{
+							{
+								Type:     "vertical",
+								Title:    "logs",
+								Commands: []string{"tail -f log", "make test"},
+							},
+						},
+					},
+				},
+			},
+			[]string{"windows[1].editor.panes[1].tests"},
+		},
+		"tmuxp": {
+			convert.FormatTmuxp,
+			`
+session_name: blog
+start_directory: ~/code/blog
+shell_command_before: source .env
+environment:
+  FOO: bar
+windows:
+  - window_name: editor
+    layout: tiled
+    focus: true
+    options:
+      automatic-rename: on
+    panes:
+      - vim
+      - shell_command:
+          - cmd: make watch
+        start_directory: ./web
+        focus: true
+`,
+			client.Config{
+				Session: "blog",
+				Path:    "~/code/blog",
+				Env:     map[string]string{"FOO": "bar"},
+				Windows: []client.Window{
+					{
+						Name:     "editor",
+						Layout:   "tiled",
+						Focus:    true,
+						Commands: []string{"source .env", "vim"},
+						Panes: []client.Pane{
+							{
+								Type:     "vertical",
+								Path:     "./web",
+								Focus:    true,
+								Commands: []string{"source .env", "make watch"},
+							},
+						},
+					},
+				},
+			},
+			[]string{"windows[0].options"},
+		},
+		"tmuxp focused first pane": {
+			convert.FormatTmuxp,
+			`
+session_name: blog
+windows:
+  - window_name: editor
+    panes:
+      - shell_command: vim
+        focus: true
+      - make watch
+`,
+			client.Config{
+				Session: "blog",
+				Windows: []client.Window{
+					{
+						Name:     "editor",
+						Commands: []string{"vim"},
+						Panes: []client.Pane{
+							{Type: "vertical", Commands: []string{"make watch"}},
+						},
+					},
+				},
+			},
+			[]string{"windows[0].panes[0].focus"},
+		},
+		"smug": {
+			convert.FormatSmug,
+			`
+session: blog
+root: ~/code/blog
+attach: true
+before_start:
+  - docker compose up -d
+stop:
+  - docker compose stop
+windows:
+  - name: code
+    layout: main-horizontal
+    commands:
+      - vim
+    panes:
+      - type: horizontal
+        root: web
+        commands:
+          - make watch
+`,
+			client.Config{
+				Session: "blog",
+				Path:    "~/code/blog",
+				Before:  []string{"docker compose up -d"},
+				After:   []string{"docker compose stop"},
+				Windows: []client.Window{
+					{
+						Name:     "code",
+						Layout:   "main-horizontal",
+						Commands: []string{"vim"},
+						Panes: []client.Pane{
+							{
+								Type:     "horizontal",
+								Path:     "web",
+								Commands: []string{"make watch"},
+							},
+						},
+					},
+				},
+			},
+			[]string{"attach"},
+		},
+	}
+
+	for testDescription, params := range testTable {
+		t.Run(testDescription, func(t *testing.T) {
+			config, dropped, err := convert.Import(params.format, []byte(params.input))
+			assert.NoError(t, err)
+			assert.Equal(t, params.expected, config)
+			assert.Equal(t, params.dropped, dropped)
+		})
+	}
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	_, _, err := convert.Import("teamocil", []byte("name: foo"))
+	assert.ErrorIs(t, err, convert.ErrUnknownFormat)
+}
+
+func TestExport(t *testing.T) {
+	config := client.Config{
+		Session: "blog",
+		Path:    "~/code/blog",
+		Before:  []string{"docker compose up -d"},
+		Env:     map[string]string{"JIG_SESSION": "blog"},
+		Windows: []client.Window{
+			{
+				Name:   "code",
+				Layout: "main-vertical",
+				Cmd:    "vim",
+				Panes: []client.Pane{
+					{Type: "horizontal", Path: "web", Cmd: "make watch"},
+				},
+			},
+			{Name: "infra", Manual: true},
+		},
+	}
+
+	testTable := map[string]struct {
+		format   convert.Format
+		expected string
+		dropped  []string
+	}{
+		"tmuxinator": {
+			convert.FormatTmuxinator,
+			`name: blog
+root: ~/code/blog
+on_project_start:
+  - docker compose up -d
+windows:
+  - code:
+      layout: main-vertical
+      panes:
+        - vim
+        - - cd web
+          - make watch
+  - infra: {}
+`,
+			[]string{"windows[0].panes[0].type", "windows[1].manual"},
+		},
+		"tmuxp": {
+			convert.FormatTmuxp,
+			`session_name: blog
+start_directory: ~/code/blog
+windows:
+  - window_name: code
+    layout: main-vertical
+    panes:
+      - shell_command:
+          - vim
+      - shell_command:
+          - make watch
+        start_directory: web
+  - window_name: infra
+    panes:
+      - {}
+`,
+			[]string{"before", "windows[0].panes[0].type", "windows[1].manual"},
+		},
+	}
+
+	for testDescription, params := range testTable {
+		t.Run(testDescription, func(t *testing.T) {
+			raw, dropped, err := convert.Export(params.format, config)
+			assert.NoError(t, err)
+			assert.Equal(t, params.expected, string(raw))
+			assert.Equal(t, params.dropped, dropped)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rafi/jig/pkg/client"
+)
+
+type tmuxinatorConfig struct {
+	Name           string                        `yaml:"name"`
+	Root           string                        `yaml:"root,omitempty"`
+	OnProjectStart []string                      `yaml:"on_project_start,omitempty"`
+	OnProjectStop  []string                      `yaml:"on_project_stop,omitempty"`
+	StartupWindow  string                        `yaml:"startup_window,omitempty"`
+	Windows        []map[string]tmuxinatorWindow `yaml:"windows"`
+}
+
+type tmuxinatorWindow struct {
+	Root   string `yaml:"root,omitempty"`
+	Layout string `yaml:"layout,omitempty"`
+	Panes  []any  `yaml:"panes,omitempty"`
+}
+
+type tmuxpConfig struct {
+	SessionName     string            `yaml:"session_name"`
+	StartDirectory  string            `yaml:"start_directory,omitempty"`
+	Environment     map[string]string `yaml:"environment,omitempty"`
+	SuppressHistory bool              `yaml:"suppress_history,omitempty"`
+	Windows         []tmuxpWindow     `yaml:"windows"`
+}
+
+type tmuxpWindow struct {
+	WindowName     string      `yaml:"window_name"`
+	StartDirectory string      `yaml:"start_directory,omitempty"`
+	Layout         string      `yaml:"layout,omitempty"`
+	Focus          bool        `yaml:"focus,omitempty"`
+	Panes          []tmuxpPane `yaml:"panes"`
+}
+
+type tmuxpPane struct {
+	ShellCommand   []string `yaml:"shell_command,omitempty"`
+	StartDirectory string   `yaml:"start_directory,omitempty"`
+	Focus          bool     `yaml:"focus,omitempty"`
+}
+
+// Export converts a jig config into a foreign format. It also returns a
+// sorted list of jig keys that have no equivalent and were dropped.
+func Export(format Format, config client.Config) ([]byte, []string, error) {
+	var exp exporter
+	var out any
+	switch format {
+	case FormatTmuxinator:
+		out = exp.tmuxinator(config)
+	case FormatTmuxp:
+		out = exp.tmuxp(config)
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+
+	var buf bytes.Buffer
+	e := yaml.NewEncoder(&buf)
+	e.SetIndent(2)
+	if err := e.Encode(out); err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(exp.dropped)
+	return buf.Bytes(), exp.dropped, nil
+}
+
+// exporter collects the jig keys it had to drop.
+type exporter struct {
+	dropped []string
+}
+
+// drop records a key when the value is set.
+func (e *exporter) drop(key string, isSet bool) {
+	if isSet {
+		e.dropped = append(e.dropped, key)
+	}
+}
+
+// dropWindow records the keys of a window and its panes which neither
+// tmuxinator nor tmuxp support.
+func (e *exporter) dropWindow(prefix string, w client.Window) {
+	e.drop(prefix+"manual", w.Manual)
+	e.drop(prefix+"before", len(w.Before) > 0)
+	e.drop(prefix+"split", w.Split != "")
+	for pidx, p := range w.Panes {
+		panePrefix := fmt.Sprintf("%spanes[%d].", prefix, pidx)
+		e.drop(panePrefix+"type", p.Type != "")
+		e.drop(panePrefix+"split", p.Split != "")
+		e.drop(panePrefix+"panes", len(p.Panes) > 0)
+	}
+}
+
+// tmuxinator converts a config into a tmuxinator project. Pane directories
+// are entered with `cd`, as tmuxinator has no per-pane root.
+func (e *exporter) tmuxinator(c client.Config) tmuxinatorConfig {
+	e.drop("env", len(c.UserEnv()) > 0)
+	e.drop("sessions", len(c.Sessions) > 0)
+	e.drop("suppress_history", c.SuppressHistory)
+
+	out := tmuxinatorConfig{
+		Name:           c.Session,
+		Root:           c.Path,
+		OnProjectStart: c.Before,
+		OnProjectStop:  c.After,
+		Windows:        []map[string]tmuxinatorWindow{},
+	}
+	for idx, w := range c.Windows {
+		prefix := fmt.Sprintf("windows[%d].", idx)
+		e.dropWindow(prefix, w)
+		if w.Focus {
+			out.StartupWindow = w.Name
+		}
+
+		win := tmuxinatorWindow{Root: w.Path, Layout: w.Layout}
+		// A window without commands nor panes has no panes key, rather than
+		// a single empty pane.
+		if len(w.GetCommands()) > 0 || len(w.Panes) > 0 {
+			win.Panes = []any{tmuxinatorPane(w.GetCommands(), "")}
+		}
+		for pidx, p := range w.Panes {
+			e.drop(fmt.Sprintf("%spanes[%d].focus", prefix, pidx), p.Focus)
+			win.Panes = append(win.Panes, tmuxinatorPane(p.GetCommands(), p.Path))
+		}
+		out.Windows = append(out.Windows, map[string]tmuxinatorWindow{w.Name: win})
+	}
+	return out
+}
+
+// tmuxinatorPane returns a pane as a single command or a list of commands.
+func tmuxinatorPane(cmds []string, path string) any {
+	if path != "" {
+		cmds = append([]string{"cd " + path}, cmds...)
+	}
+	switch len(cmds) {
+	case 0:
+		return nil
+	case 1:
+		return cmds[0]
+	}
+	return cmds
+}
+
+// tmuxp converts a config into a tmuxp workspace.
+func (e *exporter) tmuxp(c client.Config) tmuxpConfig {
+	e.drop("before", len(c.Before) > 0)
+	e.drop("after", len(c.After) > 0)
+	e.drop("sessions", len(c.Sessions) > 0)
+
+	out := tmuxpConfig{
+		SessionName:     c.Session,
+		StartDirectory:  c.Path,
+		Environment:     c.UserEnv(),
+		SuppressHistory: c.SuppressHistory,
+		Windows:         []tmuxpWindow{},
+	}
+	for idx, w := range c.Windows {
+		e.dropWindow(fmt.Sprintf("windows[%d].", idx), w)
+		win := tmuxpWindow{
+			WindowName:     w.Name,
+			StartDirectory: w.Path,
+			Layout:         w.Layout,
+			Focus:          w.Focus,
+			Panes:          []tmuxpPane{{ShellCommand: w.GetCommands()}},
+		}
+		for _, p := range w.Panes {
+			win.Panes = append(win.Panes, tmuxpPane{
+				ShellCommand:   p.GetCommands(),
+				StartDirectory: p.Path,
+				Focus:          p.Focus,
+			})
+		}
+		out.Windows = append(out.Windows, win)
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package convert
+
+import (
+	"fmt"
+
+	"github.com/rafi/jig/pkg/client"
+)
+
+// smug converts a smug project, which is the closest format to jig.
+// See https://github.com/ivaaaan/smug
+func (i *importer) smug(raw map[string]any) {
+	i.drop("", raw, "session", "root", "before_start", "stop", "env", "windows")
+
+	c := &i.config
+	c.Session = toString(raw["session"])
+	c.Path = toString(raw["root"])
+	c.Before = toStrings(raw["before_start"])
+	c.After = toStrings(raw["stop"])
+	c.Env = toEnv(raw["env"])
+
+	windows, _ := raw["windows"].([]any)
+	for idx, item := range windows {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		prefix := fmt.Sprintf("windows[%d].", idx)
+		i.drop(prefix, m, "name", "root", "manual", "layout", "commands", "panes")
+		w := client.Window{
+			Name:     toString(m["name"]),
+			Path:     toString(m["root"]),
+			Manual:   toBool(m["manual"]),
+			Layout:   toString(m["layout"]),
+			Commands: toStrings(m["commands"]),
+		}
+		rawPanes, _ := m["panes"].([]any)
+		for pidx, rawPane := range rawPanes {
+			pm, ok := rawPane.(map[string]any)
+			if !ok {
+				continue
+			}
+			i.drop(fmt.Sprintf("%spanes[%d].", prefix, pidx), pm, "type", "root", "commands")
+			w.Panes = append(w.Panes, client.Pane{
+				Type:     toString(pm["type"]),
+				Path:     toString(pm["root"]),
+				Commands: toStrings(pm["commands"]),
+			})
+		}
+		c.Windows = append(c.Windows, w)
+	}
+}
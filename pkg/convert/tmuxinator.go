@@ -0,0 +1,84 @@
+package convert
+
+import (
+	"fmt"
+
+	"github.com/rafi/jig/pkg/client"
+)
+
+// tmuxinator converts a tmuxinator project.
+// See https://github.com/tmuxinator/tmuxinator
+func (i *importer) tmuxinator(raw map[string]any) {
+	i.drop("", raw,
+		"name", "project_name", "root", "project_root", "pre",
+		"on_project_start", "on_project_stop", "pre_window", "windows",
+	)
+
+	c := &i.config
+	c.Session = toString(raw["name"])
+	if c.Session == "" {
+		c.Session = toString(raw["project_name"])
+	}
+	c.Path = toString(raw["root"])
+	if c.Path == "" {
+		c.Path = toString(raw["project_root"])
+	}
+	c.Before = append(toStrings(raw["pre"]), toStrings(raw["on_project_start"])...)
+	c.After = toStrings(raw["on_project_stop"])
+
+	preWindow := toStrings(raw["pre_window"])
+	windows, _ := raw["windows"].([]any)
+	for idx, item := range windows {
+		// Each window is a single-key mapping of its name to its definition.
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(m) {
+			prefix := fmt.Sprintf("windows[%d].%s.", idx, name)
+			c.Windows = append(c.Windows, i.tmuxinatorWindow(prefix, name, m[name], preWindow))
+		}
+	}
+}
+
+// tmuxinatorWindow converts a window definition, which is either a command,
+// a list of commands, or a mapping with panes.
+func (i *importer) tmuxinatorWindow(prefix, name string, def any, pre []string) client.Window {
+	w := client.Window{Name: name}
+	m, ok := def.(map[string]any)
+	if !ok {
+		w.Commands = append(append([]string{}, pre...), toStrings(def)...)
+		return w
+	}
+
+	i.drop(prefix, m, "root", "layout", "panes", "pre")
+	w.Path = toString(m["root"])
+	w.Layout = toString(m["layout"])
+	pre = append(append([]string{}, pre...), toStrings(m["pre"])...)
+
+	rawPanes, ok := m["panes"].([]any)
+	if !ok {
+		w.Commands = pre
+		return w
+	}
+	panes := []client.Pane{}
+	for idx, rawPane := range rawPanes {
+		pane := client.Pane{Commands: append([]string{}, pre...)}
+		// Named panes are a single-key mapping of a title to commands.
+		if named, ok := rawPane.(map[string]any); ok {
+			for _, title := range sortedKeys(named) {
+				if pane.Title == "" {
+					pane.Title = title
+				} else {
+					i.dropped = append(i.dropped, fmt.Sprintf("%spanes[%d].%s", prefix, idx, title))
+				}
+				pane.Commands = append(pane.Commands, toStrings(named[title])...)
+			}
+		} else {
+			pane.Commands = append(pane.Commands, toStrings(rawPane)...)
+		}
+		panes = append(panes, pane)
+	}
+	i.splitPanes(prefix, &w, panes)
+	return w
+}
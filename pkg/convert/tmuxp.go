@@ -0,0 +1,91 @@
+package convert
+
+import (
+	"fmt"
+
+	"github.com/rafi/jig/pkg/client"
+)
+
+// tmuxp converts a tmuxp workspace.
+// See https://tmuxp.git-pull.com/configuration/
+func (i *importer) tmuxp(raw map[string]any) {
+	i.drop("", raw,
+		"session_name", "start_directory", "before_script",
+		"shell_command_before", "environment", "suppress_history", "windows",
+	)
+
+	c := &i.config
+	c.Session = toString(raw["session_name"])
+	c.Path = toString(raw["start_directory"])
+	c.Before = toStrings(raw["before_script"])
+	c.Env = toEnv(raw["environment"])
+	c.SuppressHistory = toBool(raw["suppress_history"])
+
+	before := tmuxpCommands(raw["shell_command_before"])
+	windows, _ := raw["windows"].([]any)
+	for idx, item := range windows {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		prefix := fmt.Sprintf("windows[%d].", idx)
+		c.Windows = append(c.Windows, i.tmuxpWindow(prefix, m, before))
+	}
+}
+
+// tmuxpWindow converts a window definition.
+func (i *importer) tmuxpWindow(prefix string, m map[string]any, before []string) client.Window {
+	i.drop(prefix, m,
+		"window_name", "start_directory", "layout", "focus",
+		"shell_command_before", "panes",
+	)
+	w := client.Window{
+		Name:   toString(m["window_name"]),
+		Path:   toString(m["start_directory"]),
+		Layout: toString(m["layout"]),
+		Focus:  toBool(m["focus"]),
+	}
+	before = append(append([]string{}, before...), tmuxpCommands(m["shell_command_before"])...)
+
+	rawPanes, ok := m["panes"].([]any)
+	if !ok {
+		w.Commands = before
+		return w
+	}
+	panes := []client.Pane{}
+	for idx, rawPane := range rawPanes {
+		pane := client.Pane{Commands: append([]string{}, before...)}
+		if pm, ok := rawPane.(map[string]any); ok {
+			i.drop(fmt.Sprintf("%spanes[%d].", prefix, idx), pm,
+				"shell_command", "start_directory", "focus",
+			)
+			pane.Commands = append(pane.Commands, tmuxpCommands(pm["shell_command"])...)
+			pane.Path = toString(pm["start_directory"])
+			pane.Focus = toBool(pm["focus"])
+		} else {
+			pane.Commands = append(pane.Commands, tmuxpCommands(rawPane)...)
+		}
+		panes = append(panes, pane)
+	}
+	i.splitPanes(prefix, &w, panes)
+	return w
+}
+
+// tmuxpCommands returns a list of commands, where each may be a string, or a
+// mapping with a `cmd` key.
+func tmuxpCommands(v any) []string {
+	list, ok := v.([]any)
+	if !ok {
+		return toStrings(v)
+	}
+	cmds := []string{}
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			item = m["cmd"]
+		}
+		if s := toString(item); s != "" {
+			cmds = append(cmds, s)
+		}
+	}
+	return cmds
+}
@@ -1,67 +1,153 @@
 package shell
 
 import (
-	"log"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
 	"os/exec"
-	"os/user"
-	"path/filepath"
 	"strings"
+	"time"
 )
 
-var _ Commander = DefaultCommander{}
+var _ ContextCommander = DefaultCommander{}
 
+// stopDelay is how long a stopped command may take to exit after it was
+// terminated, before it's killed.
+const stopDelay = 5 * time.Second
+
+// DefaultCommander executes commands, and logs each one with its duration
+// at the debug level, if it has a logger.
 type DefaultCommander struct {
-	Logger *log.Logger
+	Logger *slog.Logger
 }
 
 // Exec executes a command and returns its output.
 func (c DefaultCommander) Exec(cmd *exec.Cmd) (string, error) {
-	if c.Logger != nil {
-		c.Logger.Println(strings.Join(cmd.Args, " "))
-	}
+	return c.ExecContext(context.Background(), cmd)
+}
 
-	output, err := cmd.CombinedOutput()
+// ExecContext executes a command and returns its output. The command and its
+// children are stopped when the context is done. The error output is kept
+// for the error.
+func (c DefaultCommander) ExecContext(ctx context.Context, cmd *exec.Cmd) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := run(ctx, cmd)
 	if err != nil {
-		if c.Logger != nil {
-			c.Logger.Println(err, string(output))
+		// The reason is on stderr, unless the command printed it to stdout.
+		output := stderr.String()
+		if strings.TrimSpace(output) == "" {
+			output = stdout.String()
+		}
+		err = &ShellError{
+			Command: strings.Join(cmd.Args, " "),
+			Err:     err,
+			Output:  strings.TrimSpace(output),
 		}
-		return "", &ShellError{strings.Join(cmd.Args, " "), err}
 	}
-
-	return strings.TrimSuffix(string(output), "\n"), nil
+	c.log(ctx, cmd, start, err)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
 }
 
 // ExecSilently executes a command without returning its output.
 func (c DefaultCommander) ExecSilently(cmd *exec.Cmd) error {
-	if c.Logger != nil {
-		c.Logger.Println(strings.Join(cmd.Args, " "))
-	}
+	return c.ExecSilentlyContext(context.Background(), cmd)
+}
 
-	err := cmd.Run()
+// ExecSilentlyContext executes a command without returning its output. The
+// command and its children are stopped when the context is done. Its error
+// output is kept for the error, unless it's redirected.
+func (c DefaultCommander) ExecSilentlyContext(ctx context.Context, cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	if cmd.Stderr == nil {
+		cmd.Stderr = &stderr
+	}
+	start := time.Now()
+	err := run(ctx, cmd)
 	if err != nil {
-		if c.Logger != nil {
-			c.Logger.Println(err)
+		err = &ShellError{
+			Command: strings.Join(cmd.Args, " "),
+			Err:     err,
+			Output:  strings.TrimSpace(stderr.String()),
 		}
-		return &ShellError{strings.Join(cmd.Args, " "), err}
 	}
-	return nil
+	c.log(ctx, cmd, start, err)
+	return err
 }
 
-// ExpandPath expands a relative or home-relative path to an absolute path.
-func ExpandPath(path string) string {
-	if filepath.IsAbs(path) {
-		return path
+// log logs a command which ran since start. Failures are logged at the
+// debug level too, as some are expected, e.g. of probes.
+func (c DefaultCommander) log(ctx context.Context, cmd *exec.Cmd, start time.Time, err error) {
+	if c.Logger == nil {
+		return
 	}
-	if strings.HasPrefix(path, "~/") {
-		user, err := user.Current()
-		if err != nil {
-			return path
-		}
-		return filepath.Join(user.HomeDir, path[2:])
+	attrs := []slog.Attr{
+		slog.String("cmd", strings.Join(cmd.Args, " ")),
+		slog.Duration("duration", time.Since(start)),
 	}
-	abspath, err := filepath.Abs(path)
-	if err != nil {
-		return path
+	var shellErr *ShellError
+	if errors.As(err, &shellErr) {
+		attrs = append(attrs,
+			slog.String("err", shellErr.Err.Error()),
+			slog.Int("exit_code", shellErr.ExitCode()),
+			slog.String("output", shellErr.Output),
+		)
+	}
+	c.Logger.LogAttrs(ctx, slog.LevelDebug, "exec", attrs...)
+}
+
+// run runs a command until it exits, or until the context is done. Commands
+// which can be stopped run in their own process group, so that a shell's
+// children are terminated with it, rather than kept running in the
+// background, see setProcessGroup. Children which outlive the command don't
+// keep it waiting for its output for longer than stopDelay. The context's
+// error is returned if the command was stopped, or interrupted on jig's
+// terminal.
+func run(ctx context.Context, cmd *exec.Cmd) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return cmd.Run()
+	}
+
+	restore := setProcessGroup(cmd)
+	cmd.WaitDelay = stopDelay
+	if err := cmd.Start(); err != nil {
+		restore()
+		return err
+	}
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-exited:
+			return
+		case <-ctx.Done():
+		}
+		terminate(cmd)
+		select {
+		case <-exited:
+		case <-time.After(stopDelay):
+			kill(cmd)
+		}
+	}()
+	err := cmd.Wait()
+	close(exited)
+	interrupted := restore()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return ctx.Err()
+	case interrupted:
+		return context.Canceled
+	case errors.Is(err, exec.ErrWaitDelay):
+		// The command succeeded, and its children kept its output open.
+		return nil
 	}
-	return abspath
+	return err
 }
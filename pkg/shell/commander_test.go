@@ -1,13 +1,18 @@
 package shell_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rafi/jig/pkg/shell"
 )
@@ -20,11 +25,23 @@ func TestMain(m *testing.M) {
 		fmt.Println(strings.Join(os.Args[1:], " "))
 	case "exit":
 		os.Exit(42)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "can't find session: 42")
+		os.Exit(1)
+	case "terminal":
+		// The shell's child keeps the output open once the shell is
+		// stopped, and the terminal is read once the command stopped.
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		cmd := exec.Command("/bin/sh", "-c", "sleep 8; true")
+		_, err := shell.DefaultCommander{}.ExecContext(ctx, cmd)
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		fmt.Printf("stopped: %v, read: %s", errors.Is(err, context.DeadlineExceeded), line)
 	}
 }
 
 func TestExec(t *testing.T) {
-	logger := log.New(bytes.NewBuffer([]byte{}), "", 0)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	commander := shell.DefaultCommander{logger}
 
 	cmd := exec.Command(os.Args[0], "42")
@@ -41,7 +58,8 @@ func TestExec(t *testing.T) {
 }
 
 func TestExecError(t *testing.T) {
-	logger := log.New(bytes.NewBuffer([]byte{}), "", 0)
+	out := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	commander := shell.DefaultCommander{logger}
 
 	cmd := exec.Command(os.Args[0], "42")
@@ -56,10 +74,41 @@ func TestExecError(t *testing.T) {
 	if got != 42 {
 		t.Errorf("expected %d, got %d", 42, got)
 	}
+	if !strings.Contains(out.String(), "exit_code=42") {
+		t.Errorf("expected the exit code in the log, got %q", out.String())
+	}
+}
+
+func TestExecErrorOutput(t *testing.T) {
+	commander := shell.DefaultCommander{}
+	for _, silently := range []bool{false, true} {
+		cmd := exec.Command(os.Args[0], "42")
+		cmd.Env = append(os.Environ(), "JIG_TEST_COMMANDER=fail")
+
+		var err error
+		if silently {
+			err = commander.ExecSilently(cmd)
+		} else {
+			_, err = commander.Exec(cmd)
+		}
+		var shellErr *shell.ShellError
+		if !errors.As(err, &shellErr) {
+			t.Fatalf("expected shell error, got %v", err)
+		}
+		if shellErr.Output != "can't find session: 42" {
+			t.Errorf("expected the error output, got %q", shellErr.Output)
+		}
+		if !strings.HasSuffix(err.Error(), "exit status 1: can't find session: 42") {
+			t.Errorf("expected the error output in %q", err)
+		}
+		if code := shellErr.ExitCode(); code != 1 {
+			t.Errorf("expected %d, got %d", 1, code)
+		}
+	}
 }
 
 func TestExecSilently(t *testing.T) {
-	logger := log.New(bytes.NewBuffer([]byte{}), "", 0)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	commander := shell.DefaultCommander{logger}
 
 	cmd := exec.Command(os.Args[0], "42")
@@ -72,7 +121,7 @@ func TestExecSilently(t *testing.T) {
 }
 
 func TestExecSilentlyError(t *testing.T) {
-	logger := log.New(bytes.NewBuffer([]byte{}), "", 0)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	commander := shell.DefaultCommander{logger}
 
 	cmd := exec.Command(os.Args[0], "42")
@@ -88,3 +137,72 @@ func TestExecSilentlyError(t *testing.T) {
 		t.Errorf("expected %d, got %d", 42, got)
 	}
 }
+
+func TestExecContextTimeout(t *testing.T) {
+	commander := shell.DefaultCommander{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The shell's background child keeps the output open, unless it's
+	// stopped with the shell.
+	start := time.Now()
+	cmd := exec.Command("/bin/sh", "-c", "sleep 10 & wait")
+	_, err := commander.ExecContext(ctx, cmd)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the command to stop, took %s", elapsed)
+	}
+}
+
+func TestExecContextTimeoutTerminal(t *testing.T) {
+	script, err := exec.LookPath("script")
+	if runtime.GOOS != "linux" || err != nil {
+		t.Skip("script of util-linux not found")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Commands run on a terminal are stopped with their children, and give
+	// the terminal back once they're stopped.
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, script, "-qec", shell.Quote(os.Args[0]), "/dev/null")
+	cmd.Env = append(os.Environ(), "JIG_TEST_COMMANDER=terminal")
+	cmd.Stdin = strings.NewReader("done\n")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("unexpected error %v: %s", err, output)
+	}
+	if !strings.Contains(string(output), "stopped: true, read: done") {
+		t.Errorf("expected the command to stop, got %q", output)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the command to stop, took %s", elapsed)
+	}
+}
+
+func TestExecContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Commanders which can't stop commands don't start them.
+	commander := &shell.ScriptCommander{}
+	err := shell.ExecSilentlyContext(ctx, commander, exec.Command("true"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+	if script := commander.String(); strings.Contains(script, "true") {
+		t.Errorf("expected no commands, got %q", script)
+	}
+
+	cmd := exec.Command(os.Args[0], "42")
+	cmd.Env = append(os.Environ(), "JIG_TEST_COMMANDER=echo")
+	_, err = shell.ExecContext(ctx, shell.DefaultCommander{}, cmd)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+	if cmd.Process != nil {
+		t.Errorf("expected the command not to start")
+	}
+}
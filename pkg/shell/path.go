@@ -0,0 +1,52 @@
+package shell
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandPath expands environment variables and a leading ~ or ~user in a
+// path, and makes it absolute, relative to the current directory.
+func ExpandPath(path string) string {
+	return ResolvePath(path, "")
+}
+
+// ResolvePath expands a path as ExpandPath does, but makes a relative path
+// absolute relative to a directory, e.g. of a config file, instead of the
+// current directory if the directory is empty.
+func ResolvePath(path, dir string) string {
+	path = expandHome(os.ExpandEnv(path))
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if dir != "" {
+		path = filepath.Join(dir, path)
+	}
+	abspath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abspath
+}
+
+// expandHome replaces a leading ~ with the current user's home directory,
+// or ~user with the user's. Unknown users are kept as-is.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~") {
+		return path
+	}
+	name, rest, _ := strings.Cut(path[1:], string(filepath.Separator))
+	var u *user.User
+	var err error
+	if name == "" {
+		u, err = user.Current()
+	} else {
+		u, err = user.Lookup(name)
+	}
+	if err != nil {
+		return path
+	}
+	return filepath.Join(u.HomeDir, rest)
+}
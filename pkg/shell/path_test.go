@@ -0,0 +1,51 @@
+package shell_test
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/rafi/jig/pkg/shell"
+)
+
+func TestResolvePath(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JIG_TEST_DIR", "/data")
+
+	testTable := []struct {
+		path     string
+		dir      string
+		expected string
+	}{
+		{"/srv/app", "/base", "/srv/app"},
+		{"src", "/base", "/base/src"},
+		{"../src", "/base/app", "/base/src"},
+		{"src", "", filepath.Join(cwd, "src")},
+		{"", "/base", "/base"},
+		{"$JIG_TEST_DIR/code", "/base", "/data/code"},
+		{"${JIG_TEST_DIR}/x", "", "/data/x"},
+		{"logs/$JIG_TEST_UNSET", "/base", "/base/logs"},
+		{"~", "/base", u.HomeDir},
+		{"~/code", "/base", filepath.Join(u.HomeDir, "code")},
+		{"~" + u.Username + "/code", "/base", filepath.Join(u.HomeDir, "code")},
+		{"~jig-no-such-user/code", "/base", "/base/~jig-no-such-user/code"},
+	}
+
+	for _, v := range testTable {
+		got := shell.ResolvePath(v.path, v.dir)
+		if got != v.expected {
+			t.Errorf("ResolvePath(%q, %q): expected %q, got %q", v.path, v.dir, v.expected, got)
+		}
+	}
+	if got := shell.ExpandPath("src"); got != filepath.Join(cwd, "src") {
+		t.Errorf("ExpandPath: expected a path relative to the current directory, got %q", got)
+	}
+}
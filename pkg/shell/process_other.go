@@ -0,0 +1,20 @@
+//go:build !unix
+
+package shell
+
+import "os/exec"
+
+// setProcessGroup is a no-op, only the command itself is stopped.
+func setProcessGroup(cmd *exec.Cmd) (restore func() bool) {
+	return func() bool { return false }
+}
+
+// terminate kills the command, as it can't be asked to exit.
+func terminate(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
+
+// kill kills the command.
+func kill(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
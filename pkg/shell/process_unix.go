@@ -0,0 +1,66 @@
+//go:build unix
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminal is held by the command in the foreground of jig's terminal, as
+// only one process group can read it at a time.
+var terminal sync.Mutex
+
+// setProcessGroup starts a command in a new process group, so that its
+// children are stopped with it. When jig is in the foreground of a terminal,
+// the group is moved to the foreground in its place, as commands may prompt
+// on it, e.g. sudo for a password, and a background group would be stopped
+// reading it. It returns a function which moves jig back to the foreground
+// once the command exited, and returns true if the command got the
+// terminal's interrupt, which is sent to jig as well then.
+func setProcessGroup(cmd *exec.Cmd) (restore func() bool) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	fd := int(os.Stdin.Fd())
+	terminal.Lock()
+	pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+	if err != nil || pgrp != unix.Getpgrp() {
+		terminal.Unlock()
+		return func() bool { return false }
+	}
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = fd
+	return func() bool {
+		// A background process group is stopped when it sets the
+		// foreground, unless it ignores the signal.
+		signal.Ignore(syscall.SIGTTOU)
+		_ = unix.IoctlSetPointerInt(fd, unix.TIOCSPGRP, unix.Getpgrp())
+		signal.Reset(syscall.SIGTTOU)
+		terminal.Unlock()
+
+		status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+		if !ok || !status.Signaled() || status.Signal() != syscall.SIGINT {
+			return false
+		}
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+		return true
+	}
+}
+
+// terminate asks a command's process group to exit.
+func terminate(cmd *exec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// kill kills a command's process group.
+func kill(cmd *exec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
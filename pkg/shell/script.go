@@ -0,0 +1,119 @@
+package shell
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var _ Commander = &ScriptCommander{}
+
+// ScriptCommander records commands as a POSIX shell script instead of
+// executing them.
+type ScriptCommander struct {
+	// Skip decides which commands to omit and report as failed, e.g. probes
+	// for state which the script checks itself.
+	Skip func(cmd *exec.Cmd) bool
+
+	// Capture decides which commands' output is captured into a shell
+	// variable, e.g. identifiers of created objects. A reference to the
+	// variable is returned as the command's output.
+	Capture func(cmd *exec.Cmd) bool
+
+	lines  []string
+	indent int
+	vars   int
+}
+
+var (
+	scriptVarPattern  = regexp.MustCompile(`\$\{jig_\d+\}`)
+	scriptSafePattern = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+)
+
+// Exec records a command and returns a variable reference to its output.
+func (c *ScriptCommander) Exec(cmd *exec.Cmd) (string, error) {
+	if c.Skip != nil && c.Skip(cmd) {
+		return "", &ShellError{Command: strings.Join(cmd.Args, " "), Err: ErrSkipped}
+	}
+	line := c.format(cmd)
+	if c.Capture == nil || !c.Capture(cmd) {
+		c.Println(line)
+		return "", nil
+	}
+	c.vars++
+	name := fmt.Sprintf("jig_%d", c.vars)
+	c.Println(fmt.Sprintf("%s=$(%s)", name, line))
+	return "${" + name + "}", nil
+}
+
+// ExecSilently records a command.
+func (c *ScriptCommander) ExecSilently(cmd *exec.Cmd) error {
+	if c.Skip != nil && c.Skip(cmd) {
+		return &ShellError{Command: strings.Join(cmd.Args, " "), Err: ErrSkipped}
+	}
+	c.Println(c.format(cmd))
+	return nil
+}
+
+// Println appends a raw line to the script at the current indentation.
+func (c *ScriptCommander) Println(line string) {
+	c.lines = append(c.lines, strings.Repeat("\t", c.indent)+line)
+}
+
+// Indent increases or decreases the indentation of following lines.
+func (c *ScriptCommander) Indent(delta int) {
+	c.indent = max(c.indent+delta, 0)
+}
+
+// String returns the recorded script.
+func (c *ScriptCommander) String() string {
+	return strings.Join(c.lines, "\n") + "\n"
+}
+
+// format returns a command as a shell line. Inline `sh -c` scripts are
+// copied verbatim, and a working directory is entered within a subshell.
+func (c *ScriptCommander) format(cmd *exec.Cmd) string {
+	var line string
+	if len(cmd.Args) == 3 && cmd.Args[0] == "/bin/sh" && cmd.Args[1] == "-c" {
+		line = cmd.Args[2]
+	} else {
+		args := make([]string, len(cmd.Args))
+		for i, arg := range cmd.Args {
+			args[i] = Quote(arg)
+		}
+		line = strings.Join(args, " ")
+	}
+	if cmd.Dir != "" {
+		line = fmt.Sprintf("(cd %s && %s)", Quote(cmd.Dir), line)
+	}
+	return line
+}
+
+// Quote quotes a string for a POSIX shell. References to captured variables
+// are kept expandable.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if scriptSafePattern.MatchString(s) {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range scriptVarPattern.FindAllStringIndex(s, -1) {
+		b.WriteString(quoteLiteral(s[last:loc[0]]))
+		b.WriteString(`"` + s[loc[0]:loc[1]] + `"`)
+		last = loc[1]
+	}
+	b.WriteString(quoteLiteral(s[last:]))
+	return b.String()
+}
+
+// quoteLiteral single-quotes a string, leaving empty strings as is.
+func quoteLiteral(s string) string {
+	if s == "" || scriptSafePattern.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -1,20 +1,78 @@
 package shell
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strings"
 )
 
+var ErrSkipped = errors.New("command skipped")
+
 type Commander interface {
 	Exec(cmd *exec.Cmd) (string, error)
 	ExecSilently(cmd *exec.Cmd) error
 }
 
+// ContextCommander is a Commander which stops commands when a context is
+// done, e.g. when they time out or jig is interrupted.
+type ContextCommander interface {
+	Commander
+	ExecContext(ctx context.Context, cmd *exec.Cmd) (string, error)
+	ExecSilentlyContext(ctx context.Context, cmd *exec.Cmd) error
+}
+
+// ExecContext executes a command with a commander, which stops it when the
+// context is done if it's a ContextCommander. Other commanders only don't
+// start it once the context is done.
+func ExecContext(ctx context.Context, c Commander, cmd *exec.Cmd) (string, error) {
+	if cc, ok := c.(ContextCommander); ok {
+		return cc.ExecContext(ctx, cmd)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", &ShellError{Command: strings.Join(cmd.Args, " "), Err: err}
+	}
+	return c.Exec(cmd)
+}
+
+// ExecSilentlyContext executes a command without returning its output, as
+// with ExecContext.
+func ExecSilentlyContext(ctx context.Context, c Commander, cmd *exec.Cmd) error {
+	if cc, ok := c.(ContextCommander); ok {
+		return cc.ExecSilentlyContext(ctx, cmd)
+	}
+	if err := ctx.Err(); err != nil {
+		return &ShellError{Command: strings.Join(cmd.Args, " "), Err: err}
+	}
+	return c.ExecSilently(cmd)
+}
+
 type ShellError struct {
 	Command string
 	Err     error
+
+	// Output is what the command printed, e.g. the reason it failed.
+	Output string
 }
 
 func (e *ShellError) Error() string {
-	return fmt.Sprintf("Cannot run %q. Error: %v", e.Command, e.Err)
+	if e.Output == "" {
+		return fmt.Sprintf("Cannot run %q. Error: %v", e.Command, e.Err)
+	}
+	return fmt.Sprintf("Cannot run %q. Error: %v: %s", e.Command, e.Err, e.Output)
+}
+
+// ExitCode returns the exit status of the command, or -1 if it didn't exit,
+// e.g. as it was not found or was stopped.
+func (e *ShellError) ExitCode() int {
+	var exitErr *exec.ExitError
+	if errors.As(e.Err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func (e *ShellError) Unwrap() error {
+	return e.Err
 }
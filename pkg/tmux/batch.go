@@ -0,0 +1,171 @@
+package tmux
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Batch accumulates commands which set up a window, and runs them in a
+// single tmux process, chained with ";". Panes created in a batch are
+// referred to by placeholders, which are resolved to pane indexes as
+// commands are added, and to pane IDs once the batch ran.
+type Batch struct {
+	client   TmuxClient
+	base     int
+	panes    []string
+	active   string
+	commands [][]string
+	created  []string
+	ids      map[string]string
+}
+
+// NewBatch starts a batch of commands for a window with a single pane.
+func (t TmuxClient) NewBatch(window Target) (*Batch, error) {
+	window.Pane = ""
+	base, err := t.PaneIndex(window)
+	if err != nil {
+		return nil, err
+	}
+	return &Batch{
+		client: t,
+		base:   base,
+		panes:  []string{""},
+		ids:    map[string]string{},
+	}, nil
+}
+
+// Len returns the number of commands in the batch.
+func (b *Batch) Len() int {
+	return len(b.commands)
+}
+
+// NewPane adds a split of a pane, which is inserted after it, and returns
+// the new pane's placeholder.
+func (b *Batch) NewPane(
+	target Target,
+	dir, split, size string,
+	env map[string]string,
+	command ...string,
+) (string, error) {
+	parent := target.Pane
+	if parent == "" {
+		parent = b.active
+	}
+	pos := slices.Index(b.panes, parent)
+	if pos < 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnknownPane, target.Get())
+	}
+
+	args, err := b.client.newPaneArgs(b.resolve(target), dir, split, size, env, command...)
+	if err != nil {
+		return "", err
+	}
+	pane := fmt.Sprintf("{batch-%d}", len(b.created))
+	b.panes = slices.Insert(b.panes, pos+1, pane)
+	b.created = append(b.created, pane)
+	b.commands = append(b.commands, args)
+	return pane, nil
+}
+
+// SelectPane adds a selection of the active pane.
+func (b *Batch) SelectPane(target Target) error {
+	if target.Pane != "" {
+		b.active = target.Pane
+	}
+	b.commands = append(b.commands, []string{"select-pane", "-t", b.resolve(target).Get()})
+	return nil
+}
+
+// SetPaneTitle adds setting the title of a pane, unless tmux doesn't support
+// titles.
+func (b *Batch) SetPaneTitle(target Target, title string) error {
+	if !b.client.Supports(FeaturePaneTitles) {
+		return nil
+	}
+	b.commands = append(b.commands, []string{"select-pane", "-t", b.resolve(target).Get(), "-T", title})
+	return nil
+}
+
+// SelectLayout adds selecting a layout for the window.
+func (b *Batch) SelectLayout(target Target, layout string) (string, error) {
+	b.commands = append(b.commands, []string{"select-layout", "-t", b.resolve(target).Get(), layout})
+	return "", nil
+}
+
+// SetOption adds setting an option of a target in the given scope.
+func (b *Batch) SetOption(target Target, scope OptionScope, key, value string) error {
+	b.commands = append(b.commands, setOptionArgs(b.resolve(target), scope, key, value))
+	return nil
+}
+
+// SetHook adds setting a hook of a target in the given scope.
+func (b *Batch) SetHook(target Target, scope OptionScope, hook, command string) error {
+	b.commands = append(b.commands, setHookArgs(b.resolve(target), scope, hook, command))
+	return nil
+}
+
+// RespawnPane adds running a command in place of a pane's process.
+func (b *Batch) RespawnPane(target Target, env map[string]string, command string) error {
+	b.commands = append(b.commands, respawnPaneArgs(b.resolve(target), env, command))
+	return nil
+}
+
+// Run runs the batch's commands, and resolves the IDs of the created panes
+// from their output.
+func (b *Batch) Run() error {
+	if len(b.commands) == 0 {
+		return nil
+	}
+	args := []string{}
+	for i, command := range b.commands {
+		if i > 0 {
+			args = append(args, ";")
+		}
+		for _, arg := range command {
+			// An argument ending with ";" would end the command.
+			if strings.HasSuffix(arg, ";") {
+				arg = strings.TrimSuffix(arg, ";") + `\;`
+			}
+			args = append(args, arg)
+		}
+	}
+	out, err := b.client.exec(b.client.command(args...))
+	if err != nil {
+		return err
+	}
+
+	ids := []string{}
+	if out != "" {
+		ids = strings.Split(out, "\n")
+	}
+	if len(ids) != len(b.created) {
+		return fmt.Errorf("%w: expected %d pane IDs, got %q", ErrInvalidFormat, len(b.created), out)
+	}
+	for i, pane := range b.created {
+		b.ids[pane] = ids[i]
+	}
+	return nil
+}
+
+// Target resolves a pane placeholder in a target to its pane ID, once the
+// batch ran.
+func (b *Batch) Target(target Target) Target {
+	if id, ok := b.ids[target.Pane]; ok {
+		target.Pane = id
+	}
+	return target
+}
+
+// resolve returns a target with a pane placeholder replaced by the pane's
+// current index.
+func (b *Batch) resolve(target Target) Target {
+	if target.Pane == "" {
+		return target
+	}
+	if pos := slices.Index(b.panes, target.Pane); pos >= 0 {
+		target.Pane = strconv.Itoa(b.base + pos)
+	}
+	return target
+}
@@ -0,0 +1,91 @@
+package tmux_test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// outputCommander records commands, and returns outputs in order.
+type outputCommander struct {
+	commands []string
+	outputs  []string
+}
+
+func (c *outputCommander) Exec(cmd *exec.Cmd) (string, error) {
+	c.commands = append(c.commands, strings.Join(cmd.Args, " "))
+	output := ""
+	if len(c.outputs) > 0 {
+		output, c.outputs = c.outputs[0], c.outputs[1:]
+	}
+	return output, nil
+}
+
+func (c *outputCommander) ExecSilently(cmd *exec.Cmd) error {
+	_, err := c.Exec(cmd)
+	return err
+}
+
+func TestBatch(t *testing.T) {
+	commander := &outputCommander{outputs: []string{"1", "%2\n%3\n%4"}}
+	client := tmux.TmuxClient{Bin: "tmux", Cmd: commander}
+	window := tmux.Target{Session: "ses", Window: "@1"}
+
+	batch, err := client.NewBatch(window)
+	require.NoError(t, err)
+
+	// Split the first pane, then split it again, which inserts the new pane
+	// before the previous one.
+	right, err := batch.NewPane(window, "/tmp", "horizontal", "40%", nil)
+	require.NoError(t, err)
+	left, err := batch.NewPane(window, "", "vertical", "", map[string]string{"TERM": "xterm"}, "htop")
+	require.NoError(t, err)
+	rightTarget := tmux.Target{Session: "ses", Window: "@1", Pane: right}
+	assert.NoError(t, batch.SetPaneTitle(rightTarget, "right"))
+	assert.NoError(t, batch.SelectPane(rightTarget))
+
+	// Split the active pane, which is now the right one.
+	bottom, err := batch.NewPane(window, "", "vertical", "", nil)
+	require.NoError(t, err)
+	bottomTarget := tmux.Target{Session: "ses", Window: "@1", Pane: bottom}
+	assert.NoError(t, batch.RespawnPane(bottomTarget, nil, "echo done;"))
+	assert.NoError(t, batch.SetOption(window, tmux.OptionScopeWindow, "synchronize-panes", "on"))
+	assert.Equal(t, 7, batch.Len())
+
+	_, err = batch.NewPane(tmux.Target{Session: "ses", Window: "@1", Pane: "%9"}, "", "vertical", "", nil)
+	assert.ErrorIs(t, err, tmux.ErrUnknownPane)
+
+	require.NoError(t, batch.Run())
+	assert.Equal(t, []string{
+		"tmux display-message -p -t ses:@1 #{pane_index}",
+		"tmux split-window -Pd -t ses:@1 -h -l 40% -c /tmp -F #{pane_id} ; " +
+			"split-window -Pd -t ses:@1 -v -F #{pane_id} -e TERM=xterm htop ; " +
+			"select-pane -t ses:@1.3 -T right ; " +
+			"select-pane -t ses:@1.3 ; " +
+			"split-window -Pd -t ses:@1 -v -F #{pane_id} ; " +
+			`respawn-pane -k -t ses:@1.4 echo done\; ; ` +
+			"set-option -w -t ses:@1 synchronize-panes on",
+	}, commander.commands)
+
+	assert.Equal(t, "%2", batch.Target(rightTarget).Pane)
+	assert.Equal(t, "%3", batch.Target(tmux.Target{Pane: left}).Pane)
+	assert.Equal(t, "%4", batch.Target(bottomTarget).Pane)
+	assert.Equal(t, window, batch.Target(window))
+}
+
+func TestBatchInvalidOutput(t *testing.T) {
+	commander := &outputCommander{outputs: []string{"0", ""}}
+	client := tmux.TmuxClient{Bin: "tmux", Cmd: commander}
+	window := tmux.Target{Session: "ses", Window: "@1"}
+
+	batch, err := client.NewBatch(window)
+	require.NoError(t, err)
+	_, err = batch.NewPane(window, "", "vertical", "", nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, batch.Run(), tmux.ErrInvalidFormat)
+}
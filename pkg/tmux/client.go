@@ -0,0 +1,40 @@
+package tmux
+
+import "os"
+
+var _ Client = TmuxClient{}
+
+// Client covers the tmux operations jig performs. TmuxClient runs them with
+// the tmux command, and tmuxtest.Server on an in-memory model of a server.
+type Client interface {
+	NewSession(name, dir, windowName string, command ...string) (string, error)
+	NewWindow(target Target, name, dir string, env map[string]string, command ...string) (string, error)
+	NewPane(target Target, dir, split, size string, env map[string]string, command ...string) (string, error)
+	RespawnPane(target Target, env map[string]string, command string) error
+	KillWindow(target Target) error
+	SendKeys(target Target, command string) error
+	Attach(session string, stdin, stdout, stderr *os.File) error
+	SwitchClient(session string) error
+	SessionExists(name string) bool
+	SessionName() (string, error)
+	SetEnv(session, key, value string) (string, error)
+	SetOption(target Target, scope OptionScope, key, value string) error
+	SetHook(target Target, scope OptionScope, hook, command string) error
+	KeyBinding(table, key string) string
+	BindKey(table, key string, command ...string) error
+	UnbindKey(table, key string) error
+	RenumberWindows(session string) error
+	SelectLayout(target Target, layout string) (string, error)
+	WindowSize(target Target) (TmuxWindowSize, error)
+	CapturePane(target Target) (string, error)
+	PaneIndex(target Target) (int, error)
+	SelectWindow(target Target) error
+	SelectPane(target Target) error
+	SetPaneTitle(target Target, title string) error
+	StopSession(target Target) (string, error)
+	ListSessions() ([]TmuxSession, error)
+	ListWindows(target Target) ([]TmuxWindow, error)
+	ListPanes(target Target) ([]TmuxPane, error)
+	Supports(f Feature) bool
+	Require(f Feature) error
+}
@@ -0,0 +1,299 @@
+package tmux
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rafi/jig/pkg/shell"
+)
+
+var _ shell.Commander = &ControlCommander{}
+
+// clientCommands act on the client running them, which would be the control
+// mode client rather than the user's.
+var clientCommands = []string{
+	"attach", "attach-session", "switch-client", "switchc", "detach-client",
+	"display-message", "display",
+}
+
+// ControlCommander runs tmux commands over a single control mode connection
+// to a session, instead of starting a tmux process for each command.
+// Commands which aren't tmux's, take their own input, or act on the current
+// client are executed by the fallback commander.
+type ControlCommander struct {
+	bin      string
+	server   []string
+	fallback shell.Commander
+	logger   *slog.Logger
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	mu     sync.Mutex
+	exited chan struct{}
+
+	// pending are the replies awaited by sent commands, in the order of
+	// sending them, which tmux replies in.
+	pendingMu sync.Mutex
+	pending   []chan controlReply
+}
+
+// controlReply is the output of a command, and whether it failed.
+type controlReply struct {
+	output string
+	failed bool
+}
+
+var ErrControlClosed = errors.New("tmux control mode connection closed")
+
+// NewControlCommander attaches a control mode client to a session of the
+// client's server, and falls back to the client's commander, whose logger it
+// logs commands with, if it's a shell.DefaultCommander.
+func NewControlCommander(client TmuxClient, session string) (*ControlCommander, error) {
+	if err := client.Require(FeatureControlFlags); err != nil {
+		return nil, err
+	}
+	cmd := client.command("-C", "attach", "-f", "ignore-size,no-output", "-t", session)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, &shell.ShellError{Command: strings.Join(cmd.Args, " "), Err: err}
+	}
+
+	c := &ControlCommander{
+		bin:      client.Bin,
+		server:   client.ServerArgs(),
+		fallback: client.Cmd,
+		logger:   commanderLogger(client.Cmd),
+		cmd:      cmd,
+		stdin:    stdin,
+		exited:   make(chan struct{}),
+	}
+	attached := make(chan controlReply, 1)
+	go c.read(stdout, attached)
+
+	select {
+	case reply := <-attached:
+		if reply.failed {
+			_ = c.Close()
+			return nil, &shell.ShellError{
+				Command: strings.Join(cmd.Args, " "),
+				Err:     errors.New(reply.output),
+			}
+		}
+	case <-c.exited:
+		_ = cmd.Wait()
+		err := ErrControlClosed
+		select {
+		case reply := <-attached:
+			if reply.failed {
+				err = errors.New(reply.output)
+			}
+		default:
+		}
+		return nil, &shell.ShellError{Command: strings.Join(cmd.Args, " "), Err: err}
+	}
+	return c, nil
+}
+
+// Exec runs a command and returns its output.
+func (c *ControlCommander) Exec(cmd *exec.Cmd) (string, error) {
+	args, ok := c.commandArgs(cmd)
+	if !ok {
+		return c.fallback.Exec(cmd)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	start := time.Now()
+	out, err := c.send(args)
+	if err != nil {
+		err = &shell.ShellError{Command: strings.Join(cmd.Args, " "), Err: err}
+	}
+	if c.logger != nil {
+		attrs := []slog.Attr{
+			slog.String("cmd", strings.Join(cmd.Args, " ")),
+			slog.Duration("duration", time.Since(start)),
+			slog.Bool("control", true),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("err", err.Error()))
+		}
+		c.logger.LogAttrs(context.Background(), slog.LevelDebug, "exec", attrs...)
+	}
+	return out, err
+}
+
+// send sends a command, and returns its output, or tmux's error message.
+func (c *ControlCommander) send(args []string) (string, error) {
+	line := strings.Join(quoteArgs(args), " ") + "\n"
+	replies := make(chan controlReply, 1)
+	c.pendingMu.Lock()
+	c.pending = append(c.pending, replies)
+	c.pendingMu.Unlock()
+	if _, err := io.WriteString(c.stdin, line); err != nil {
+		c.dropPending(replies)
+		return "", err
+	}
+	select {
+	case reply := <-replies:
+		if reply.failed {
+			return "", errors.New(reply.output)
+		}
+		return reply.output, nil
+	case <-c.exited:
+		return "", ErrControlClosed
+	}
+}
+
+// dropPending stops awaiting a reply of a command which wasn't sent.
+func (c *ControlCommander) dropPending(replies chan controlReply) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	if i := slices.Index(c.pending, replies); i >= 0 {
+		c.pending = slices.Delete(c.pending, i, i+1)
+	}
+}
+
+// reply passes the reply of a sent command to its waiter, or drops it if
+// nobody waits for it.
+func (c *ControlCommander) reply(reply controlReply) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	if len(c.pending) == 0 {
+		return
+	}
+	c.pending[0] <- reply
+	c.pending = c.pending[1:]
+}
+
+// commanderLogger returns the logger of a commander, if it has one.
+func commanderLogger(c shell.Commander) *slog.Logger {
+	if d, ok := c.(shell.DefaultCommander); ok {
+		return d.Logger
+	}
+	return nil
+}
+
+// ExecSilently runs a command without returning its output.
+func (c *ControlCommander) ExecSilently(cmd *exec.Cmd) error {
+	if _, ok := c.commandArgs(cmd); !ok {
+		return c.fallback.ExecSilently(cmd)
+	}
+	_, err := c.Exec(cmd)
+	return err
+}
+
+// Close detaches the control mode client.
+func (c *ControlCommander) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.stdin.Close(); err != nil {
+		return err
+	}
+	<-c.exited
+	return c.cmd.Wait()
+}
+
+// commandArgs returns the arguments of a tmux command without the flags
+// selecting the server, and true if it can run over the connection.
+func (c *ControlCommander) commandArgs(cmd *exec.Cmd) ([]string, bool) {
+	if len(cmd.Args) < 2 || cmd.Args[0] != c.bin {
+		return nil, false
+	}
+	if cmd.Stdin != nil || cmd.Stdout != nil || cmd.Stderr != nil {
+		return nil, false
+	}
+	args := cmd.Args[1:]
+	if !slices.Equal(args[:min(len(c.server), len(args))], c.server) {
+		return nil, false
+	}
+	args = args[len(c.server):]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || slices.Contains(clientCommands, args[0]) {
+		return nil, false
+	}
+	// Commands are sent line by line.
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "\r\n") {
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+// read parses the output of the control mode client. Output of commands
+// sent by jig is framed by %begin and %end or %error lines with a flags
+// field of 1, and any other line starting with % is a notification, which
+// is ignored.
+func (c *ControlCommander) read(r io.Reader, attached chan<- controlReply) {
+	defer close(c.exited)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var output []string
+	inBlock := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inBlock {
+			fields := strings.Fields(line)
+			if len(fields) == 4 && (fields[0] == "%end" || fields[0] == "%error") {
+				inBlock = false
+				reply := controlReply{
+					output: strings.Join(output, "\n"),
+					failed: fields[0] == "%error",
+				}
+				if fields[3] == "1" {
+					c.reply(reply)
+					continue
+				}
+				select {
+				case attached <- reply:
+				default:
+				}
+				continue
+			}
+			output = append(output, line)
+			continue
+		}
+
+		if strings.HasPrefix(line, "%begin ") {
+			inBlock = true
+			output = nil
+		}
+	}
+}
+
+// quoteArgs quotes arguments for tmux's command parser. As on the command
+// line, an argument ending with ";" ends a command, unless it's escaped.
+func quoteArgs(args []string) []string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		end := false
+		if strings.HasSuffix(arg, `\;`) {
+			arg = strings.TrimSuffix(arg, `\;`) + ";"
+		} else if strings.HasSuffix(arg, ";") {
+			arg = strings.TrimSuffix(arg, ";")
+			end = true
+		}
+		if arg != "" || !end {
+			quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+		}
+		if end {
+			quoted = append(quoted, ";")
+		}
+	}
+	return quoted
+}
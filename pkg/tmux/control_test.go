@@ -0,0 +1,109 @@
+package tmux_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// fakeControlTmux echoes each command it receives as its output, or fails
+// on unknown commands, and notifies of a new window before each reply. It
+// replies once more after its input is closed, with nobody waiting.
+const fakeControlTmux = `#!/bin/sh
+echo '%begin 1 1 0'
+echo '%end 1 1 0'
+while IFS= read -r line; do
+	echo '%window-add @1'
+	echo '%begin 1 2 1'
+	case "$line" in
+	*bogus*)
+		echo 'unknown command: bogus'
+		echo '%error 1 2 1'
+		;;
+	*)
+		printf '%s\n' "$line"
+		echo '%end 1 2 1'
+		;;
+	esac
+done
+echo '%begin 1 3 1'
+echo '%end 1 3 1'
+echo '%exit'
+`
+
+type recordingCommander struct {
+	commands []string
+}
+
+func (c *recordingCommander) Exec(cmd *exec.Cmd) (string, error) {
+	c.commands = append(c.commands, strings.Join(cmd.Args, " "))
+	return "fallback", nil
+}
+
+func (c *recordingCommander) ExecSilently(cmd *exec.Cmd) error {
+	_, err := c.Exec(cmd)
+	return err
+}
+
+func TestControlCommander(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "tmux")
+	require.NoError(t, os.WriteFile(bin, []byte(fakeControlTmux), 0o755))
+
+	fallback := &recordingCommander{}
+	client := tmux.TmuxClient{Bin: bin, Cmd: fallback, SocketName: "work"}
+	control, err := tmux.NewControlCommander(client, "ses")
+	require.NoError(t, err)
+
+	// Commands of the client's server are sent without the server flags.
+	client.Cmd = control
+	out, err := client.NewWindow(tmux.Target{Session: "ses"}, "it's", "/tmp", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `'new-window' '-Pd' '-t' 'ses:' '-n' 'it'\''s' '-F' '#{window_id}' '-c' '/tmp'`, out)
+
+	// Commands are chained as on the command line.
+	out, err = control.Exec(exec.Command(bin, "-L", "work", "select-pane", "-T", "a;", "select-pane", "-T", `b\;`))
+	assert.NoError(t, err)
+	assert.Equal(t, `'select-pane' '-T' 'a' ; 'select-pane' '-T' 'b;'`, out)
+
+	_, err = control.Exec(exec.Command(bin, "-L", "work", "bogus"))
+	var shellErr *shell.ShellError
+	require.ErrorAs(t, err, &shellErr)
+	assert.EqualError(t, shellErr.Err, "unknown command: bogus")
+
+	// Commands acting on the current client, with input, or of other
+	// programs or servers are executed by the fallback commander.
+	out, err = control.Exec(exec.Command(bin, "-L", "work", "display-message", "-p", "#S"))
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback", out)
+	assert.NoError(t, client.SendKeys(tmux.Target{Session: "ses"}, "echo 1\necho 2"))
+	_, err = control.Exec(exec.Command("/bin/sh", "-c", "true"))
+	assert.NoError(t, err)
+	_, err = control.Exec(exec.Command(bin, "-L", "personal", "list-sessions"))
+	assert.NoError(t, err)
+
+	require.NoError(t, control.Close())
+	assert.Equal(t, []string{
+		bin + " -L work display-message -p #S",
+		bin + " -L work send-keys -t ses: -l echo 1\necho 2",
+		"/bin/sh -c true",
+		bin + " -L personal list-sessions",
+	}, fallback.commands)
+}
+
+func TestControlCommanderAttachError(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "tmux")
+	script := "#!/bin/sh\necho '%begin 1 1 0'\necho \"can't find session: ses\"\necho '%error 1 1 0'\n"
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0o755))
+
+	client := tmux.TmuxClient{Bin: bin, Cmd: &recordingCommander{}}
+	_, err := tmux.NewControlCommander(client, "ses")
+	assert.ErrorContains(t, err, "can't find session: ses")
+}
@@ -0,0 +1,94 @@
+package tmux
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/rafi/jig/pkg/shell"
+)
+
+// Errors of tmux commands, by the reason tmux reported.
+var (
+	ErrNoServer         = errors.New("no tmux server running")
+	ErrSessionNotFound  = errors.New("session not found")
+	ErrWindowNotFound   = errors.New("window not found")
+	ErrPaneNotFound     = errors.New("pane not found")
+	ErrDuplicateSession = errors.New("duplicate session")
+	ErrInvalidOption    = errors.New("invalid option")
+	ErrUnknownCommand   = errors.New("unknown command")
+	ErrNoSpace          = errors.New("no space for new pane")
+)
+
+// tmuxErrors maps the messages of tmux to errors.
+var tmuxErrors = []struct {
+	message string
+	err     error
+}{
+	{"no server running", ErrNoServer},
+	{"error connecting to", ErrNoServer},
+	{"can't find session", ErrSessionNotFound},
+	{"can't find window", ErrWindowNotFound},
+	{"can't find pane", ErrPaneNotFound},
+	{"duplicate session", ErrDuplicateSession},
+	{"invalid layout", ErrInvalidLayout},
+	{"unknown layout", ErrInvalidLayout},
+	{"invalid option", ErrInvalidOption},
+	{"ambiguous option", ErrInvalidOption},
+	{"unknown value", ErrInvalidOption},
+	{"invalid value", ErrInvalidOption},
+	{"unknown command", ErrUnknownCommand},
+	{"no space for new pane", ErrNoSpace},
+}
+
+// Error is a tmux command which failed for a known reason, e.g.
+// ErrSessionNotFound. It matches both the reason and the command's error.
+type Error struct {
+	Reason error
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Reason, e.Err}
+}
+
+// classify returns the error of a tmux command as an Error, if tmux reported
+// a known reason.
+func classify(err error) error {
+	var shellErr *shell.ShellError
+	if !errors.As(err, &shellErr) {
+		return err
+	}
+	message := shellErr.Output
+	if message == "" {
+		message = shellErr.Err.Error()
+	}
+	for _, e := range tmuxErrors {
+		if strings.Contains(message, e.message) {
+			return &Error{Reason: e.err, Err: err}
+		}
+	}
+	return err
+}
+
+// exec executes a tmux command, and classifies its error.
+func (t TmuxClient) exec(cmd *exec.Cmd) (string, error) {
+	out, err := t.Cmd.Exec(cmd)
+	if err != nil {
+		return out, classify(err)
+	}
+	return out, nil
+}
+
+// execSilently executes a tmux command without returning its output, and
+// classifies its error.
+func (t TmuxClient) execSilently(cmd *exec.Cmd) error {
+	if err := t.Cmd.ExecSilently(cmd); err != nil {
+		return classify(err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package tmux_test
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/pkg/shell"
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+// failingCommander fails each command with an output.
+type failingCommander struct {
+	output string
+}
+
+func (c failingCommander) Exec(cmd *exec.Cmd) (string, error) {
+	return "", &shell.ShellError{
+		Command: strings.Join(cmd.Args, " "),
+		Err:     errors.New("exit status 1"),
+		Output:  c.output,
+	}
+}
+
+func (c failingCommander) ExecSilently(cmd *exec.Cmd) error {
+	_, err := c.Exec(cmd)
+	return err
+}
+
+func TestErrors(t *testing.T) {
+	target := tmux.Target{Session: "ses", Window: "@3"}
+	testTable := []struct {
+		output string
+		run    func(c tmux.TmuxClient) error
+		err    error
+	}{
+		{
+			output: "can't find session: ses",
+			run: func(c tmux.TmuxClient) error {
+				_, err := c.StopSession(target)
+				return err
+			},
+			err: tmux.ErrSessionNotFound,
+		},
+		{
+			output: "duplicate session: ses",
+			run: func(c tmux.TmuxClient) error {
+				_, err := c.NewSession("ses", "/tmp", "")
+				return err
+			},
+			err: tmux.ErrDuplicateSession,
+		},
+		{
+			output: "invalid layout: mainvertical",
+			run: func(c tmux.TmuxClient) error {
+				_, err := c.SelectLayout(target, "mainvertical")
+				return err
+			},
+			err: tmux.ErrInvalidLayout,
+		},
+		{
+			output: "invalid option: bogus",
+			run: func(c tmux.TmuxClient) error {
+				return c.SetOption(target, tmux.OptionScopeWindow, "bogus", "1")
+			},
+			err: tmux.ErrInvalidOption,
+		},
+		{
+			output: "no space for new pane",
+			run: func(c tmux.TmuxClient) error {
+				_, err := c.NewPane(target, "/tmp", "vertical", "", nil)
+				return err
+			},
+			err: tmux.ErrNoSpace,
+		},
+	}
+
+	for _, tc := range testTable {
+		client := tmux.TmuxClient{Bin: "tmux", Cmd: failingCommander{tc.output}}
+		err := tc.run(client)
+		assert.ErrorIs(t, err, tc.err, tc.output)
+		var shellErr *shell.ShellError
+		assert.ErrorAs(t, err, &shellErr)
+		assert.ErrorContains(t, err, tc.output)
+	}
+
+	// Other errors are kept as they are.
+	client := tmux.TmuxClient{Bin: "tmux", Cmd: failingCommander{"lost server"}}
+	err := client.SelectWindow(target)
+	var tmuxErr *tmux.Error
+	assert.False(t, errors.As(err, &tmuxErr))
+}
@@ -0,0 +1,322 @@
+package tmux
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type LayoutType int
+
+const (
+	LayoutPane LayoutType = iota
+	LayoutLeftRight
+	LayoutTopBottom
+)
+
+var customLayoutPattern = regexp.MustCompile(`^[0-9a-f]{4},`)
+
+// Layout is a cell of a custom window layout, as printed by tmux's
+// `window_layout` format, e.g. "a1b2,238x61,0,0{119x61,0,0,1,118x61,120,0,2}".
+// A cell is either a pane, or a container of cells split left-right or
+// top-bottom.
+type Layout struct {
+	Type   LayoutType
+	Width  int
+	Height int
+	X      int
+	Y      int
+	PaneID int
+	Cells  []*Layout
+}
+
+// IsCustomLayout reports whether a layout is a custom layout string, rather
+// than a preset name like "tiled".
+func IsCustomLayout(layout string) bool {
+	return customLayoutPattern.MatchString(layout)
+}
+
+// ParseLayout parses a custom layout string and validates its checksum.
+func ParseLayout(layout string) (*Layout, error) {
+	if !IsCustomLayout(layout) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLayout, layout)
+	}
+	checksum, body := layout[:4], layout[5:]
+	if fmt.Sprintf("%04x", LayoutChecksum(body)) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch in %q", ErrInvalidLayout, layout)
+	}
+
+	p := layoutParser{input: body}
+	cell, err := p.parseCell()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	return cell, nil
+}
+
+// LayoutChecksum computes the checksum tmux prefixes to layout strings.
+func LayoutChecksum(layout string) uint16 {
+	var csum uint16
+	for i := 0; i < len(layout); i++ {
+		csum = (csum >> 1) + ((csum & 1) << 15)
+		csum += uint16(layout[i])
+	}
+	return csum
+}
+
+// String returns the layout as a string with a valid checksum.
+func (l *Layout) String() string {
+	var b strings.Builder
+	l.write(&b)
+	body := b.String()
+	return fmt.Sprintf("%04x,%s", LayoutChecksum(body), body)
+}
+
+// PaneCount returns the number of panes in the layout.
+func (l *Layout) PaneCount() int {
+	if l.Type == LayoutPane {
+		return 1
+	}
+	count := 0
+	for _, cell := range l.Cells {
+		count += cell.PaneCount()
+	}
+	return count
+}
+
+// Resize scales the layout to a new window size, keeping the proportions of
+// all cells and a single cell border between them. It returns an error if
+// the size is too small to fit every pane.
+func (l *Layout) Resize(width, height int) error {
+	minWidth, minHeight := l.minSize()
+	if width < minWidth || height < minHeight {
+		return fmt.Errorf("%w: %d panes need at least %dx%d, window is %dx%d",
+			ErrInvalidLayout, l.PaneCount(), minWidth, minHeight, width, height)
+	}
+	l.resize(width, height)
+	l.move(0, 0)
+	return nil
+}
+
+// minSize returns the smallest size of a cell, with every pane at least one
+// cell wide and high.
+func (l *Layout) minSize() (width, height int) {
+	if l.Type == LayoutPane || len(l.Cells) == 0 {
+		return 1, 1
+	}
+	for i, cell := range l.Cells {
+		w, h := cell.minSize()
+		if l.Type == LayoutLeftRight {
+			width += w
+			height = max(height, h)
+			if i > 0 {
+				width++
+			}
+		} else {
+			width = max(width, w)
+			height += h
+			if i > 0 {
+				height++
+			}
+		}
+	}
+	return width, height
+}
+
+// minLength returns the smallest size of a cell along the axis of its
+// parent's split.
+func (l *Layout) minLength(split LayoutType) int {
+	w, h := l.minSize()
+	if split == LayoutLeftRight {
+		return w
+	}
+	return h
+}
+
+// resize sets the size of a cell and proportionally distributes it among its
+// child cells. Each child gets at least its minimum size, so the size must
+// fit the cell, see minSize.
+func (l *Layout) resize(width, height int) {
+	l.Width, l.Height = width, height
+	if l.Type == LayoutPane || len(l.Cells) == 0 {
+		return
+	}
+
+	// Space for the children, without borders between them.
+	total, oldTotal, minTotal := width, 0, 0
+	if l.Type == LayoutTopBottom {
+		total = height
+	}
+	total -= len(l.Cells) - 1
+	for _, cell := range l.Cells {
+		if l.Type == LayoutLeftRight {
+			oldTotal += cell.Width
+		} else {
+			oldTotal += cell.Height
+		}
+		minTotal += cell.minLength(l.Type)
+	}
+
+	remaining := total
+	for i, cell := range l.Cells {
+		// Keep enough space for the minimum of the cells after this one.
+		minimum := cell.minLength(l.Type)
+		minTotal -= minimum
+		size := remaining
+		if i < len(l.Cells)-1 {
+			old := cell.Width
+			if l.Type == LayoutTopBottom {
+				old = cell.Height
+			}
+			size = min(max(old*total/max(oldTotal, 1), minimum), remaining-minTotal)
+		}
+		remaining -= size
+		if l.Type == LayoutLeftRight {
+			cell.resize(size, height)
+		} else {
+			cell.resize(width, size)
+		}
+	}
+}
+
+// move sets the offset of a cell and lays out its children after each other.
+func (l *Layout) move(x, y int) {
+	l.X, l.Y = x, y
+	for _, cell := range l.Cells {
+		cell.move(x, y)
+		if l.Type == LayoutLeftRight {
+			x += cell.Width + 1
+		} else {
+			y += cell.Height + 1
+		}
+	}
+}
+
+// write appends the layout body, without checksum, to a builder.
+func (l *Layout) write(b *strings.Builder) {
+	fmt.Fprintf(b, "%dx%d,%d,%d", l.Width, l.Height, l.X, l.Y)
+	switch l.Type {
+	case LayoutPane:
+		if l.PaneID >= 0 {
+			fmt.Fprintf(b, ",%d", l.PaneID)
+		}
+		return
+	case LayoutLeftRight:
+		b.WriteByte('{')
+	case LayoutTopBottom:
+		b.WriteByte('[')
+	}
+	for i, cell := range l.Cells {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		cell.write(b)
+	}
+	if l.Type == LayoutLeftRight {
+		b.WriteByte('}')
+	} else {
+		b.WriteByte(']')
+	}
+}
+
+// layoutParser is a recursive descent parser of layout bodies.
+type layoutParser struct {
+	input string
+	pos   int
+}
+
+// parseCell parses "WxH,X,Y" followed by a pane ID or a list of cells.
+func (p *layoutParser) parseCell() (*Layout, error) {
+	var err error
+	cell := &Layout{PaneID: -1}
+	if cell.Width, err = p.parseNumber('x'); err != nil {
+		return nil, err
+	}
+	if cell.Height, err = p.parseNumber(','); err != nil {
+		return nil, err
+	}
+	if cell.X, err = p.parseNumber(','); err != nil {
+		return nil, err
+	}
+	if cell.Y, err = p.parseNumber(0); err != nil {
+		return nil, err
+	}
+
+	switch p.peek() {
+	case '{', '[':
+		cell.Type = LayoutLeftRight
+		closing := byte('}')
+		if p.peek() == '[' {
+			cell.Type = LayoutTopBottom
+			closing = ']'
+		}
+		p.pos++
+		for {
+			child, err := p.parseCell()
+			if err != nil {
+				return nil, err
+			}
+			cell.Cells = append(cell.Cells, child)
+			if p.peek() == closing {
+				p.pos++
+				break
+			}
+			if p.peek() != ',' {
+				return nil, p.errorf("expected ',' or %q", closing)
+			}
+			p.pos++
+		}
+
+	case ',':
+		// A pane ID follows, unless this is the next sibling's size.
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] >= '0' && p.input[end] <= '9' {
+			end++
+		}
+		if end < len(p.input) && p.input[end] == 'x' {
+			break
+		}
+		p.pos++
+		if cell.PaneID, err = p.parseNumber(0); err != nil {
+			return nil, err
+		}
+	}
+	return cell, nil
+}
+
+// parseNumber parses a decimal number, followed by an optional separator.
+func (p *layoutParser) parseNumber(sep byte) (int, error) {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	num, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		return 0, p.errorf("expected a number")
+	}
+	if sep != 0 {
+		if p.peek() != sep {
+			return 0, p.errorf("expected %q", sep)
+		}
+		p.pos++
+	}
+	return num, nil
+}
+
+// peek returns the current character, or zero at the end of input.
+func (p *layoutParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// errorf returns an invalid layout error at the current position.
+func (p *layoutParser) errorf(format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return fmt.Errorf("%w: %s at offset %d of %q", ErrInvalidLayout, msg, p.pos, p.input)
+}
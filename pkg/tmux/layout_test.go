@@ -0,0 +1,62 @@
+package tmux_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+func TestParseLayout(t *testing.T) {
+	testTable := map[string]struct {
+		layout string
+		panes  int
+		err    error
+	}{
+		"single pane":     {"b25d,80x24,0,0,0", 1, nil},
+		"left-right":      {"8be7,80x24,0,0{78x24,0,0,1,1x24,79,0,2}", 2, nil},
+		"nested":          {"d67e,80x24,0,0{40x24,0,0,0,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}", 3, nil},
+		"preset name":     {"tiled", 0, tmux.ErrInvalidLayout},
+		"bad checksum":    {"0000,80x24,0,0,0", 0, tmux.ErrInvalidLayout},
+		"unclosed parens": {"ffff,80x24,0,0{40x24,0,0,1", 0, tmux.ErrInvalidLayout},
+	}
+
+	for testDescription, params := range testTable {
+		t.Run(testDescription, func(t *testing.T) {
+			layout, err := tmux.ParseLayout(params.layout)
+			if params.err != nil {
+				assert.ErrorIs(t, err, params.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, params.panes, layout.PaneCount())
+			assert.Equal(t, params.layout, layout.String())
+		})
+	}
+}
+
+func TestLayoutResize(t *testing.T) {
+	layout, err := tmux.ParseLayout("d67e,80x24,0,0{40x24,0,0,0,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}")
+	require.NoError(t, err)
+
+	require.NoError(t, layout.Resize(161, 49))
+	expected := "161x49,0,0{81x49,0,0,0,79x49,82,0[79x25,82,0,1,79x23,82,26,2]}"
+	assert.Equal(t, expected, layout.String()[5:])
+	_, err = tmux.ParseLayout(layout.String())
+	assert.NoError(t, err)
+}
+
+func TestLayoutResizeSmall(t *testing.T) {
+	layout, err := tmux.ParseLayout("d67e,80x24,0,0{40x24,0,0,0,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}")
+	require.NoError(t, err)
+
+	// Every pane keeps at least one cell.
+	require.NoError(t, layout.Resize(3, 3))
+	assert.Equal(t, "3x3,0,0{1x3,0,0,0,1x3,2,0[1x1,2,0,1,1x1,2,2,2]}", layout.String()[5:])
+
+	err = layout.Resize(3, 2)
+	assert.ErrorIs(t, err, tmux.ErrInvalidLayout)
+	assert.ErrorContains(t, err, "3 panes need at least 3x3, window is 3x2")
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/rafi/jig/pkg/shell"
@@ -12,12 +15,37 @@ import (
 type TmuxClient struct {
 	Bin string
 	Cmd shell.Commander
+
+	// SocketName selects a tmux server by its socket name, as with -L.
+	SocketName string
+
+	// SocketPath selects a tmux server by its socket path, as with -S, and
+	// takes precedence over SocketName.
+	SocketPath string
+
+	// ConfigFile is loaded by a server started by the client, as with -f.
+	ConfigFile string
+
+	// Versions caches the detected tmux version, to check which features
+	// are supported. Without it, all features are assumed to be supported.
+	Versions *VersionCache
 }
 
 const ColumnSep = "§"
 
-// NewSession creates a new session with optional name and directory.
-func (t TmuxClient) NewSession(name, dir, windowName string) (string, error) {
+var (
+	sizePattern = regexp.MustCompile(`^[0-9]+%?$`)
+	// plainArgPattern matches arguments which need no quotes in a command.
+	plainArgPattern = regexp.MustCompile(`^[\w./:@%+=,-]+$`)
+	bindingPattern  = regexp.MustCompile(`^bind-key\s+(?:-r\s+)?-T\s+\S+\s+\S+\s+(.*)$`)
+)
+
+// NewSession creates a new session with optional name, directory and a
+// command to run in place of the shell.
+func (t TmuxClient) NewSession(
+	name, dir, windowName string,
+	command ...string,
+) (string, error) {
 	args := []string{"new-session", "-Pd", "-F", "#{session_id}"}
 	if name != "" {
 		args = append(args, "-s", name)
@@ -29,11 +57,18 @@ func (t TmuxClient) NewSession(name, dir, windowName string) (string, error) {
 	if dir != "" {
 		args = append(args, "-c", shell.ExpandPath(dir))
 	}
-	return t.Cmd.Exec(exec.Command(t.Bin, args...))
+	args = append(args, command...)
+	return t.exec(t.command(args...))
 }
 
-// NewWindow creates a new window with optional name and directory.
-func (t TmuxClient) NewWindow(target Target, name, dir string) (string, error) {
+// NewWindow creates a new window with optional name, directory and a command
+// to run in place of the shell.
+func (t TmuxClient) NewWindow(
+	target Target,
+	name, dir string,
+	env map[string]string,
+	command ...string,
+) (string, error) {
 	args := []string{"new-window", "-Pd", "-t", target.Get()}
 
 	// Naming a window will disable automatic-rename.
@@ -44,46 +79,132 @@ func (t TmuxClient) NewWindow(target Target, name, dir string) (string, error) {
 	if dir != "" {
 		args = append(args, "-c", shell.ExpandPath(dir))
 	}
+	args = append(args, EnvArgs(env)...)
+	args = append(args, command...)
 
-	cmd := exec.Command(t.Bin, args...)
-	return t.Cmd.Exec(cmd)
+	cmd := t.command(args...)
+	return t.exec(cmd)
 }
 
-// NewPane creates a new split in a session's window.
-func (t TmuxClient) NewPane(target Target, dir, split string) (string, error) {
+// NewPane creates a new split in a session's window, with an optional size
+// in cells or a percentage, e.g. "40%", and a command to run in place of the
+// shell.
+func (t TmuxClient) NewPane(
+	target Target,
+	dir, split, size string,
+	env map[string]string,
+	command ...string,
+) (string, error) {
+	args, err := t.newPaneArgs(target, dir, split, size, env, command...)
+	if err != nil {
+		return "", err
+	}
+	cmd := t.command(args...)
+	return t.exec(cmd)
+}
+
+// newPaneArgs returns the arguments of split-window. Percentages are passed
+// with -p to tmux releases which don't support them with -l.
+func (t TmuxClient) newPaneArgs(
+	target Target,
+	dir, split, size string,
+	env map[string]string,
+	command ...string,
+) ([]string, error) {
 	args := []string{"split-window", "-Pd", "-t", target.Get()}
+	flag, err := splitFlag(split)
+	if err != nil {
+		return nil, err
+	}
+	if flag != "" {
+		args = append(args, flag)
+	}
 
-	switch split {
-	case "v", "-v", "vertical":
-		args = append(args, "-v")
-	case "h", "-h", "horizontal":
-		args = append(args, "-h")
-	default:
-		fmt.Printf("Invalid split type: %s\n", split)
+	if size != "" {
+		if !sizePattern.MatchString(size) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSize, size)
+		}
+		percent, ok := strings.CutSuffix(size, "%")
+		if ok && !t.Supports(FeaturePercentSizes) {
+			args = append(args, "-p", percent)
+		} else {
+			args = append(args, "-l", size)
+		}
 	}
 
 	if dir != "" {
 		args = append(args, "-c", shell.ExpandPath(dir))
 	}
 	args = append(args, "-F", "#{pane_id}")
+	args = append(args, EnvArgs(env)...)
+	return append(args, command...), nil
+}
+
+// EnvArgs returns the -e flags which set environment variables of a new or
+// respawned pane, sorted by name.
+func EnvArgs(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	args := []string{}
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+env[key])
+	}
+	return args
+}
+
+// ValidateSplit returns an error if a split type is unknown.
+func ValidateSplit(split string) error {
+	_, err := splitFlag(split)
+	return err
+}
+
+// splitFlag returns the split-window flag of a split type. An empty type has
+// no flag, so tmux splits top and bottom by default.
+func splitFlag(split string) (string, error) {
+	switch split {
+	case "":
+		return "", nil
+	case "v", "-v", "vertical":
+		return "-v", nil
+	case "h", "-h", "horizontal":
+		return "-h", nil
+	}
+	return "", fmt.Errorf("%w: %q, expected horizontal or vertical", ErrInvalidSplitType, split)
+}
 
-	cmd := exec.Command(t.Bin, args...)
-	return t.Cmd.Exec(cmd)
+// RespawnPane kills a pane's process and runs a command in its place, or the
+// pane's command again if it's empty, with environment variables of the
+// pane.
+func (t TmuxClient) RespawnPane(target Target, env map[string]string, command string) error {
+	cmd := t.command(respawnPaneArgs(target, env, command)...)
+	return t.execSilently(cmd)
+}
+
+// respawnPaneArgs returns the arguments of respawn-pane.
+func respawnPaneArgs(target Target, env map[string]string, command string) []string {
+	args := append([]string{"respawn-pane", "-k", "-t", target.Get()}, EnvArgs(env)...)
+	if command != "" {
+		args = append(args, command)
+	}
+	return args
 }
 
 // KillWindow kills a window in a session.
 func (t TmuxClient) KillWindow(target Target) error {
-	cmd := exec.Command(t.Bin, "kill-window", "-t", target.Get())
-	_, err := t.Cmd.Exec(cmd)
+	cmd := t.command("kill-window", "-t", target.Get())
+	_, err := t.exec(cmd)
 	return err
 }
 
 // SendKeys sends key-strokes to a target.
 func (t TmuxClient) SendKeys(target Target, command string) error {
 	baseArgs := []string{"send-keys", "-t", target.Get()}
-	cmd := exec.Command(t.Bin, append(baseArgs, "-l", command)...)
-	err := t.Cmd.ExecSilently(cmd)
-	_ = t.Cmd.ExecSilently(exec.Command(t.Bin, append(baseArgs, "Enter")...))
+	cmd := t.command(append(baseArgs, "-l", command)...)
+	err := t.execSilently(cmd)
+	_ = t.execSilently(t.command(append(baseArgs, "Enter")...))
 	return err
 }
 
@@ -92,74 +213,275 @@ func (t TmuxClient) Attach(
 	session string,
 	stdin, stdout, stderr *os.File,
 ) error {
-	cmd := exec.Command(t.Bin, "attach", "-d", "-t", session)
+	cmd := t.command("attach", "-d", "-t", session)
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
-	return t.Cmd.ExecSilently(cmd)
+	return t.execSilently(cmd)
 }
 
 // SwitchClient switches to a client.
 func (t TmuxClient) SwitchClient(session string) error {
-	cmd := exec.Command(t.Bin, "switch-client", "-t", session)
-	return t.Cmd.ExecSilently(cmd)
+	cmd := t.command("switch-client", "-t", session)
+	return t.execSilently(cmd)
 }
 
 // SessionExists checks if a session exists.
 func (t TmuxClient) SessionExists(name string) bool {
-	cmd := exec.Command(t.Bin, "has-session", "-t", name+":")
-	res, err := t.Cmd.Exec(cmd)
+	cmd := t.command("has-session", "-t", name+":")
+	res, err := t.exec(cmd)
 	return res == "" && err == nil
 }
 
 // SessionName returns the current session name.
 func (t TmuxClient) SessionName() (string, error) {
-	cmd := exec.Command(t.Bin, "display-message", "-p", "#S")
-	return t.Cmd.Exec(cmd)
+	cmd := t.command("display-message", "-p", "#S")
+	return t.exec(cmd)
 }
 
 // SetEnv sets an environment variable in a session.
 func (t TmuxClient) SetEnv(session, key, value string) (string, error) {
-	cmd := exec.Command(t.Bin, "setenv", "-t", session, key, value)
-	return t.Cmd.Exec(cmd)
+	cmd := t.command("setenv", "-t", session, key, value)
+	return t.exec(cmd)
+}
+
+// SetOption sets an option of a target in the given scope.
+func (t TmuxClient) SetOption(target Target, scope OptionScope, key, value string) error {
+	cmd := t.command(setOptionArgs(target, scope, key, value)...)
+	return t.execSilently(cmd)
+}
+
+// setOptionArgs returns the arguments of set-option.
+func setOptionArgs(target Target, scope OptionScope, key, value string) []string {
+	args := []string{"set-option"}
+	switch scope {
+	case OptionScopeServer:
+		args = append(args, "-s")
+	case OptionScopeWindow:
+		args = append(args, "-w", "-t", target.Get())
+	case OptionScopePane:
+		args = append(args, "-p", "-t", target.Get())
+	default:
+		args = append(args, "-t", target.Get())
+	}
+	return append(args, key, value)
+}
+
+// SetHook sets a hook of a target in the given scope to run a command.
+func (t TmuxClient) SetHook(target Target, scope OptionScope, hook, command string) error {
+	cmd := t.command(setHookArgs(target, scope, hook, command)...)
+	return t.execSilently(cmd)
+}
+
+// setHookArgs returns the arguments of set-hook.
+func setHookArgs(target Target, scope OptionScope, hook, command string) []string {
+	args := []string{"set-hook"}
+	switch scope {
+	case OptionScopeWindow:
+		args = append(args, "-w")
+	case OptionScopePane:
+		args = append(args, "-p")
+	}
+	return append(args, "-t", target.Get(), hook, command)
+}
+
+// KeyBinding returns the command bound to a key in a key table, or an empty
+// string if the key is not bound.
+func (t TmuxClient) KeyBinding(table, key string) string {
+	cmd := t.command("list-keys", "-T", table, key)
+	out, err := t.exec(cmd)
+	if err != nil {
+		// tmux fails for unknown keys and tables.
+		return ""
+	}
+	match := bindingPattern.FindStringSubmatch(out)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// SplitCommand splits a command as printed by list-keys into its arguments,
+// as they are given on the command line: the commands of a list are
+// separated by a \; argument, and an argument ending with a semicolon ends
+// with \; instead.
+func SplitCommand(command string) []string {
+	args := []string{}
+	var arg strings.Builder
+	var quote rune
+	inArg := false
+	end := func() {
+		if !inArg {
+			return
+		}
+		word := arg.String()
+		if word != `\;` && strings.HasSuffix(word, ";") {
+			word = strings.TrimSuffix(word, ";") + `\;`
+		}
+		args = append(args, word)
+		arg.Reset()
+		inArg = false
+	}
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(runes):
+			i++
+			if runes[i] == ';' && quote == 0 && !inArg {
+				arg.WriteString(`\;`)
+			} else {
+				i += unescape(runes[i:], &arg) - 1
+			}
+			inArg = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t':
+			end()
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	end()
+	return args
+}
+
+// unescape writes the character of an escape sequence, without its
+// backslash, and returns the number of runes it takes.
+func unescape(runes []rune, arg *strings.Builder) int {
+	switch runes[0] {
+	case 'n':
+		arg.WriteByte('\n')
+	case 'r':
+		arg.WriteByte('\r')
+	case 't':
+		arg.WriteByte('\t')
+	case 'e':
+		arg.WriteByte('\033')
+	default:
+		if len(runes) >= 3 {
+			if n, err := strconv.ParseUint(string(runes[:3]), 8, 8); err == nil {
+				arg.WriteByte(byte(n))
+				return 3
+			}
+		}
+		arg.WriteRune(runes[0])
+	}
+	return 1
+}
+
+// JoinCommand joins the arguments of a command, as split by SplitCommand,
+// into a command string, e.g. to be run by if-shell.
+func JoinCommand(args []string) string {
+	words := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case arg == `\;`:
+			words = append(words, ";")
+		case arg != "" && plainArgPattern.MatchString(arg):
+			words = append(words, arg)
+		default:
+			words = append(words, quoteArgs([]string{arg})...)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// BindKey binds a key in a key table to a command, given either as a single
+// command string, or as the command's arguments.
+func (t TmuxClient) BindKey(table, key string, command ...string) error {
+	args := append([]string{"bind-key", "-T", table, key}, command...)
+	return t.execSilently(t.command(args...))
+}
+
+// UnbindKey removes a key binding from a key table.
+func (t TmuxClient) UnbindKey(table, key string) error {
+	cmd := t.command("unbind-key", "-T", table, key)
+	return t.execSilently(cmd)
 }
 
 // RenumberWindows renumbers windows' index in a session.
 func (t TmuxClient) RenumberWindows(session string) error {
-	cmd := exec.Command(t.Bin, "move-window", "-r", "-s", session, "-t", session)
-	return t.Cmd.ExecSilently(cmd)
+	cmd := t.command("move-window", "-r", "-s", session, "-t", session)
+	return t.execSilently(cmd)
 }
 
 // SelectLayout selects a layout for a window.
 func (t TmuxClient) SelectLayout(target Target, layout string) (string, error) {
-	cmd := exec.Command(t.Bin, "select-layout", "-t", target.Get(), layout)
-	return t.Cmd.Exec(cmd)
+	cmd := t.command("select-layout", "-t", target.Get(), layout)
+	return t.exec(cmd)
+}
+
+// WindowSize returns the current size of a window.
+func (t TmuxClient) WindowSize(target Target) (TmuxWindowSize, error) {
+	size := TmuxWindowSize{}
+	format := strings.Join(getFormat(size), ColumnSep)
+	cmd := t.command("display-message", "-p", "-t", target.Get(), format)
+	out, err := t.exec(cmd)
+	if err != nil {
+		return size, err
+	}
+	err = parseOutput(out, &size)
+	return size, err
+}
+
+// CapturePane returns the contents of a pane, including its history.
+func (t TmuxClient) CapturePane(target Target) (string, error) {
+	cmd := t.command("capture-pane", "-p", "-J", "-S", "-", "-t", target.Get())
+	return t.exec(cmd)
+}
+
+// PaneIndex returns the index of a target's active pane.
+func (t TmuxClient) PaneIndex(target Target) (int, error) {
+	cmd := t.command("display-message", "-p", "-t", target.Get(), "#{pane_index}")
+	out, err := t.exec(cmd)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
 }
 
 // SelectWindow selects a window in a session.
 func (t TmuxClient) SelectWindow(target Target) error {
-	cmd := exec.Command(t.Bin, "select-window", "-t", target.Get())
-	return t.Cmd.ExecSilently(cmd)
+	cmd := t.command("select-window", "-t", target.Get())
+	return t.execSilently(cmd)
 }
 
 // SelectPane selects a pane in a window.
 func (t TmuxClient) SelectPane(target Target) error {
-	cmd := exec.Command(t.Bin, "select-pane", "-t", target.Get())
-	return t.Cmd.ExecSilently(cmd)
+	cmd := t.command("select-pane", "-t", target.Get())
+	return t.execSilently(cmd)
+}
+
+// SetPaneTitle sets the title of a pane, unless tmux doesn't support titles.
+func (t TmuxClient) SetPaneTitle(target Target, title string) error {
+	if !t.Supports(FeaturePaneTitles) {
+		return nil
+	}
+	cmd := t.command("select-pane", "-t", target.Get(), "-T", title)
+	return t.execSilently(cmd)
 }
 
 // StopSession stops a session.
 func (t TmuxClient) StopSession(target Target) (string, error) {
-	cmd := exec.Command(t.Bin, "kill-session", "-t", target.Get())
-	return t.Cmd.Exec(cmd)
+	cmd := t.command("kill-session", "-t", target.Get())
+	return t.exec(cmd)
 }
 
 // ListSessions returns a list of sessions and their information.
 func (t TmuxClient) ListSessions() ([]TmuxSession, error) {
 	fields := getFormat(TmuxSession{})
 	format := strings.Join(fields, ColumnSep)
-	cmd := exec.Command(t.Bin, "list-sessions", "-F", format)
-	out, err := t.Cmd.Exec(cmd)
+	cmd := t.command("list-sessions", "-F", format)
+	out, err := t.exec(cmd)
 	if err != nil {
 		return []TmuxSession{}, err
 	}
@@ -180,8 +502,8 @@ func (t TmuxClient) ListSessions() ([]TmuxSession, error) {
 func (t TmuxClient) ListWindows(target Target) ([]TmuxWindow, error) {
 	fields := getFormat(TmuxWindow{})
 	format := strings.Join(fields, ColumnSep)
-	cmd := exec.Command(t.Bin, "list-windows", "-t", target.Get(), "-F", format)
-	out, err := t.Cmd.Exec(cmd)
+	cmd := t.command("list-windows", "-t", target.Get(), "-F", format)
+	out, err := t.exec(cmd)
 	if err != nil {
 		return []TmuxWindow{}, err
 	}
@@ -202,8 +524,8 @@ func (t TmuxClient) ListWindows(target Target) ([]TmuxWindow, error) {
 func (t TmuxClient) ListPanes(target Target) ([]TmuxPane, error) {
 	fields := getFormat(TmuxPane{})
 	format := strings.Join(fields, ColumnSep)
-	cmd := exec.Command(t.Bin, "list-panes", "-t", target.Get(), "-F", format)
-	out, err := t.Cmd.Exec(cmd)
+	cmd := t.command("list-panes", "-t", target.Get(), "-F", format)
+	out, err := t.exec(cmd)
 	if err != nil {
 		return []TmuxPane{}, err
 	}
@@ -219,3 +541,24 @@ func (t TmuxClient) ListPanes(target Target) ([]TmuxPane, error) {
 	}
 	return panes, nil
 }
+
+// ServerArgs returns the flags selecting the client's tmux server, and its
+// config file.
+func (t TmuxClient) ServerArgs() []string {
+	args := []string{}
+	if t.ConfigFile != "" {
+		args = append(args, "-f", t.ConfigFile)
+	}
+	switch {
+	case t.SocketPath != "":
+		args = append(args, "-S", t.SocketPath)
+	case t.SocketName != "":
+		args = append(args, "-L", t.SocketName)
+	}
+	return args
+}
+
+// command returns a tmux command for the client's server.
+func (t TmuxClient) command(args ...string) *exec.Cmd {
+	return exec.Command(t.Bin, append(t.ServerArgs(), args...)...)
+}
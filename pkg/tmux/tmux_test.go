@@ -0,0 +1,39 @@
+package tmux_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		joined  string
+	}{
+		{"new-window", []string{"new-window"}, "new-window"},
+		{
+			`source-file /root/.tmux.conf \; display-message reloaded`,
+			[]string{"source-file", "/root/.tmux.conf", `\;`, "display-message", "reloaded"},
+			"source-file /root/.tmux.conf ; display-message reloaded",
+		},
+		{
+			`if-shell -F "#{==:#{session_name},a#,b}" "run-shell 'make test'" "display \"hi\\there\""`,
+			[]string{"if-shell", "-F", "#{==:#{session_name},a#,b}", "run-shell 'make test'", `display "hi\there"`},
+			`if-shell -F '#{==:#{session_name},a#,b}' 'run-shell '\''make test'\''' 'display "hi\there"'`,
+		},
+		{
+			`send-keys -t 'a b' "x;" \~ "\$HOME" "\t" ''`,
+			[]string{"send-keys", "-t", "a b", `x\;`, "~", "$HOME", "\t", ""},
+			`send-keys -t 'a b' 'x;' '~' '$HOME' '` + "\t" + `' ''`,
+		},
+	}
+	for _, test := range tests {
+		args := tmux.SplitCommand(test.command)
+		assert.Equal(t, test.args, args, test.command)
+		assert.Equal(t, test.joined, tmux.JoinCommand(args), test.command)
+	}
+}
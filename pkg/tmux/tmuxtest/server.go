@@ -0,0 +1,723 @@
+// Package tmuxtest provides an in-memory model of a tmux server, which
+// implements tmux.Client, to test code driving tmux against the state of
+// sessions, windows and panes rather than the commands it runs.
+package tmuxtest
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+var _ tmux.Client = &Server{}
+
+// Errors of the server also match the errors of tmux, e.g. a missing
+// session matches both ErrNotFound and tmux.ErrSessionNotFound.
+var (
+	ErrNotFound  = errors.New("can't find")
+	ErrDuplicate = errors.New("duplicate session")
+	ErrNoClient  = errors.New("no current client")
+)
+
+var sizePattern = regexp.MustCompile(`^[0-9]+%?$`)
+
+// Server is an in-memory tmux server. Its fields may be inspected and set
+// directly, while no method is running.
+type Server struct {
+	mu sync.Mutex
+
+	Sessions []*Session
+
+	// Options and Hooks are global, e.g. set with the server scope.
+	Options map[string]string
+	Hooks   map[string]string
+
+	// Bindings are commands bound to keys, by key table.
+	Bindings map[string]map[string]string
+
+	// Client is the session of the current client, e.g. when running
+	// inside tmux, and Attached is the last session attached or switched to.
+	Client   string
+	Attached string
+
+	// Shell is reported as the command of panes without one.
+	Shell string
+
+	// Width and Height are the size of all windows.
+	Width  int
+	Height int
+
+	// Version of tmux, all features are supported when nil.
+	Version *tmux.Version
+
+	nextSession int
+	nextWindow  int
+	nextPane    int
+}
+
+type Session struct {
+	ID      string
+	Name    string
+	Path    string
+	Env     map[string]string
+	Options map[string]string
+	Hooks   map[string]string
+	Windows []*Window
+}
+
+type Window struct {
+	ID      string
+	Index   int
+	Name    string
+	Layout  string
+	Active  bool
+	Options map[string]string
+	Hooks   map[string]string
+	Panes   []*Pane
+}
+
+type Pane struct {
+	ID    string
+	Path  string
+	Title string
+
+	// Command runs in place of the shell, Split and Size are how the pane
+	// was split from its sibling.
+	Command string
+	Split   string
+	Size    string
+	Active  bool
+
+	// Env are the environment variables set for the pane, in addition to
+	// the session's.
+	Env map[string]string
+
+	// Keys are the commands typed into the pane, and Output its contents.
+	Keys   []string
+	Output string
+
+	Options map[string]string
+	Hooks   map[string]string
+}
+
+// NewServer returns an empty server, with 80x24 windows.
+func NewServer() *Server {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if shell == "." || shell == "/" {
+		shell = "sh"
+	}
+	return &Server{
+		Options:  map[string]string{},
+		Hooks:    map[string]string{},
+		Bindings: map[string]map[string]string{},
+		Shell:    shell,
+		Width:    80,
+		Height:   24,
+	}
+}
+
+// Session returns a session by name or ID, or nil if it doesn't exist.
+func (s *Server) Session(name string) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.session(name)
+}
+
+// Window returns a target's window, or nil if it doesn't exist.
+func (s *Server) Window(target tmux.Target) *Window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, w, err := s.window(target)
+	if err != nil {
+		return nil
+	}
+	return w
+}
+
+// Pane returns a target's pane, or nil if it doesn't exist.
+func (s *Server) Pane(target tmux.Target) *Pane {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, p, err := s.pane(target)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// NewSession creates a session with a single window and pane.
+func (s *Server) NewSession(name, dir, windowName string, command ...string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name == "" {
+		name = strconv.Itoa(s.nextSession)
+	}
+	if s.session(name) != nil {
+		return "", &tmux.Error{
+			Reason: tmux.ErrDuplicateSession,
+			Err:    fmt.Errorf("%w: %s", ErrDuplicate, name),
+		}
+	}
+	session := &Session{
+		ID:      fmt.Sprintf("$%d", s.nextSession),
+		Name:    name,
+		Path:    dir,
+		Env:     map[string]string{},
+		Options: map[string]string{},
+		Hooks:   map[string]string{},
+	}
+	s.nextSession++
+	w := s.newWindow(session, windowName, dir, nil, command)
+	w.Active = true
+	s.Sessions = append(s.Sessions, session)
+	return session.ID, nil
+}
+
+// NewWindow creates a window at the session's first free index.
+func (s *Server) NewWindow(target tmux.Target, name, dir string, env map[string]string, command ...string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, err := s.findSession(target.Session)
+	if err != nil {
+		return "", err
+	}
+	return s.newWindow(session, name, dir, env, command).ID, nil
+}
+
+// NewPane splits a pane, and inserts the new pane after it.
+func (s *Server) NewPane(target tmux.Target, dir, split, size string, env map[string]string, command ...string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, parent, err := s.pane(target)
+	if err != nil {
+		return "", err
+	}
+	if size != "" && !sizePattern.MatchString(size) {
+		return "", fmt.Errorf("%w: %s", tmux.ErrInvalidSize, size)
+	}
+	if err := tmux.ValidateSplit(split); err != nil {
+		return "", err
+	}
+	switch split {
+	case "h", "-h", "horizontal":
+		split = "horizontal"
+	default:
+		split = "vertical"
+	}
+	if dir == "" {
+		dir = parent.Path
+	}
+	p := s.newPane(dir, env, command)
+	p.Split = split
+	p.Size = size
+	w.Panes = slices.Insert(w.Panes, slices.Index(w.Panes, parent)+1, p)
+	return p.ID, nil
+}
+
+// RespawnPane runs a command in place of a pane's process, or the pane's
+// command again if it's empty, with environment variables added to the
+// pane's.
+func (s *Server) RespawnPane(target tmux.Target, env map[string]string, command string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, p, err := s.pane(target)
+	if err != nil {
+		return err
+	}
+	if command != "" {
+		p.Command = command
+	}
+	maps.Copy(p.Env, env)
+	return nil
+}
+
+// KillWindow removes a window, and its session if it was the last one.
+func (s *Server) KillWindow(target tmux.Target) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, w, err := s.window(target)
+	if err != nil {
+		return err
+	}
+	session.Windows = slices.DeleteFunc(session.Windows, func(other *Window) bool {
+		return other == w
+	})
+	if len(session.Windows) == 0 {
+		s.removeSession(session)
+	} else if w.Active {
+		session.Windows[0].Active = true
+	}
+	return nil
+}
+
+// SendKeys types a command into a pane.
+func (s *Server) SendKeys(target tmux.Target, command string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, p, err := s.pane(target)
+	if err != nil {
+		return err
+	}
+	p.Keys = append(p.Keys, command)
+	return nil
+}
+
+// Attach attaches the current client to a session.
+func (s *Server) Attach(session string, stdin, stdout, stderr *os.File) error {
+	return s.SwitchClient(session)
+}
+
+// SwitchClient switches the current client to a session.
+func (s *Server) SwitchClient(session string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, err := s.findSession(session)
+	if err != nil {
+		return err
+	}
+	s.Client = found.Name
+	s.Attached = found.Name
+	return nil
+}
+
+// SessionExists checks if a session exists.
+func (s *Server) SessionExists(name string) bool {
+	return s.Session(name) != nil
+}
+
+// SessionName returns the session of the current client.
+func (s *Server) SessionName() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Client == "" {
+		return "", ErrNoClient
+	}
+	return s.Client, nil
+}
+
+// SetEnv sets an environment variable in a session.
+func (s *Server) SetEnv(session, key, value string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, err := s.findSession(session)
+	if err != nil {
+		return "", err
+	}
+	found.Env[key] = value
+	return "", nil
+}
+
+// SetOption sets an option of a target in the given scope.
+func (s *Server) SetOption(target tmux.Target, scope tmux.OptionScope, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	options, _, err := s.scoped(target, scope)
+	if err != nil {
+		return err
+	}
+	options[key] = value
+	return nil
+}
+
+// SetHook sets a hook of a target in the given scope.
+func (s *Server) SetHook(target tmux.Target, scope tmux.OptionScope, hook, command string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, hooks, err := s.scoped(target, scope)
+	if err != nil {
+		return err
+	}
+	hooks[hook] = command
+	return nil
+}
+
+// KeyBinding returns the command bound to a key, or an empty string.
+func (s *Server) KeyBinding(table, key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Bindings[table][key]
+}
+
+// BindKey binds a key in a key table to a command.
+func (s *Server) BindKey(table, key string, command ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Bindings[table] == nil {
+		s.Bindings[table] = map[string]string{}
+	}
+	s.Bindings[table][key] = strings.Join(command, " ")
+	return nil
+}
+
+// UnbindKey removes a key binding from a key table.
+func (s *Server) UnbindKey(table, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Bindings[table], key)
+	return nil
+}
+
+// RenumberWindows renumbers a session's windows from its base-index.
+func (s *Server) RenumberWindows(session string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, err := s.findSession(session)
+	if err != nil {
+		return err
+	}
+	base := s.baseIndex(found, "base-index")
+	for i, w := range found.Windows {
+		w.Index = base + i
+	}
+	return nil
+}
+
+// SelectLayout sets a window's layout.
+func (s *Server) SelectLayout(target tmux.Target, layout string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, w, err := s.window(target)
+	if err != nil {
+		return "", err
+	}
+	w.Layout = layout
+	return "", nil
+}
+
+// WindowSize returns the size of a window.
+func (s *Server) WindowSize(target tmux.Target) (tmux.TmuxWindowSize, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, _, err := s.window(target); err != nil {
+		return tmux.TmuxWindowSize{}, err
+	}
+	return tmux.TmuxWindowSize{Width: s.Width, Height: s.Height}, nil
+}
+
+// CapturePane returns a pane's output.
+func (s *Server) CapturePane(target tmux.Target) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, p, err := s.pane(target)
+	if err != nil {
+		return "", err
+	}
+	return p.Output, nil
+}
+
+// PaneIndex returns the index of a target's pane.
+func (s *Server) PaneIndex(target tmux.Target) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, w, err := s.window(target)
+	if err != nil {
+		return 0, err
+	}
+	_, p, err := s.pane(target)
+	if err != nil {
+		return 0, err
+	}
+	return s.baseIndex(session, "pane-base-index") + slices.Index(w.Panes, p), nil
+}
+
+// SelectWindow makes a window the session's active one.
+func (s *Server) SelectWindow(target tmux.Target) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, w, err := s.window(target)
+	if err != nil {
+		return err
+	}
+	for _, other := range session.Windows {
+		other.Active = other == w
+	}
+	return nil
+}
+
+// SelectPane makes a pane the window's active one.
+func (s *Server) SelectPane(target tmux.Target) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, p, err := s.pane(target)
+	if err != nil {
+		return err
+	}
+	for _, other := range w.Panes {
+		other.Active = other == p
+	}
+	return nil
+}
+
+// SetPaneTitle sets the title of a pane.
+func (s *Server) SetPaneTitle(target tmux.Target, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, p, err := s.pane(target)
+	if err != nil {
+		return err
+	}
+	p.Title = title
+	return nil
+}
+
+// StopSession removes a session.
+func (s *Server) StopSession(target tmux.Target) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, err := s.findSession(target.Session)
+	if err != nil {
+		return "", err
+	}
+	s.removeSession(session)
+	return "", nil
+}
+
+// ListSessions returns all sessions.
+func (s *Server) ListSessions() ([]tmux.TmuxSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := make([]tmux.TmuxSession, 0, len(s.Sessions))
+	for _, session := range s.Sessions {
+		sessions = append(sessions, tmux.TmuxSession{
+			ID:       session.ID,
+			Name:     session.Name,
+			Path:     session.Path,
+			Attached: session.Name == s.Attached,
+			Windows:  len(session.Windows),
+		})
+	}
+	return sessions, nil
+}
+
+// ListWindows returns the windows of a session, in index order.
+func (s *Server) ListWindows(target tmux.Target) ([]tmux.TmuxWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, err := s.findSession(target.Session)
+	if err != nil {
+		return nil, err
+	}
+	windows := slices.Clone(session.Windows)
+	slices.SortStableFunc(windows, func(a, b *Window) int { return a.Index - b.Index })
+	result := make([]tmux.TmuxWindow, 0, len(windows))
+	for _, w := range windows {
+		result = append(result, tmux.TmuxWindow{
+			ID:     w.ID,
+			Name:   w.Name,
+			Layout: w.Layout,
+			Path:   activePane(w).Path,
+		})
+	}
+	return result, nil
+}
+
+// ListPanes returns the panes of a window, in index order.
+func (s *Server) ListPanes(target tmux.Target) ([]tmux.TmuxPane, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, w, err := s.window(target)
+	if err != nil {
+		return nil, err
+	}
+	panes := make([]tmux.TmuxPane, 0, len(w.Panes))
+	for _, p := range w.Panes {
+		command := s.Shell
+		if p.Command != "" {
+			command = filepath.Base(strings.Fields(p.Command)[0])
+		}
+		panes = append(panes, tmux.TmuxPane{Path: p.Path, Command: command, Title: p.Title})
+	}
+	return panes, nil
+}
+
+// Supports returns true if the server's version supports a feature.
+func (s *Server) Supports(f tmux.Feature) bool {
+	return s.Require(f) == nil
+}
+
+// Require returns an error if the server's version doesn't support a
+// feature.
+func (s *Server) Require(f tmux.Feature) error {
+	if s.Version == nil {
+		return nil
+	}
+	return s.Version.Require(f)
+}
+
+// newWindow adds a window with a single pane to a session, at its first
+// free index.
+func (s *Server) newWindow(session *Session, name, dir string, env map[string]string, command []string) *Window {
+	index := s.baseIndex(session, "base-index")
+	for slices.ContainsFunc(session.Windows, func(w *Window) bool { return w.Index == index }) {
+		index++
+	}
+	if dir == "" {
+		dir = session.Path
+	}
+	p := s.newPane(dir, env, command)
+	p.Active = true
+	if name == "" {
+		name = s.Shell
+		if p.Command != "" {
+			name = filepath.Base(strings.Fields(p.Command)[0])
+		}
+	}
+	w := &Window{
+		ID:      fmt.Sprintf("@%d", s.nextWindow),
+		Index:   index,
+		Name:    name,
+		Options: map[string]string{},
+		Hooks:   map[string]string{},
+		Panes:   []*Pane{p},
+	}
+	s.nextWindow++
+	session.Windows = append(session.Windows, w)
+	return w
+}
+
+// newPane returns a new pane running a command, if any.
+func (s *Server) newPane(dir string, env map[string]string, command []string) *Pane {
+	p := &Pane{
+		ID:      fmt.Sprintf("%%%d", s.nextPane),
+		Path:    dir,
+		Command: strings.Join(command, " "),
+		Env:     map[string]string{},
+		Options: map[string]string{},
+		Hooks:   map[string]string{},
+	}
+	maps.Copy(p.Env, env)
+	s.nextPane++
+	return p
+}
+
+// baseIndex returns the value of an index option of a session, or the
+// global one.
+func (s *Server) baseIndex(session *Session, option string) int {
+	value, ok := session.Options[option]
+	if !ok {
+		value = s.Options[option]
+	}
+	index, _ := strconv.Atoi(value)
+	return index
+}
+
+// session returns a session by name or ID, or nil.
+func (s *Server) session(name string) *Session {
+	for _, session := range s.Sessions {
+		if session.Name == name || session.ID == name {
+			return session
+		}
+	}
+	return nil
+}
+
+func (s *Server) findSession(name string) (*Session, error) {
+	if session := s.session(name); session != nil {
+		return session, nil
+	}
+	return nil, &tmux.Error{
+		Reason: tmux.ErrSessionNotFound,
+		Err:    fmt.Errorf("%w session: %s", ErrNotFound, name),
+	}
+}
+
+func (s *Server) removeSession(session *Session) {
+	s.Sessions = slices.DeleteFunc(s.Sessions, func(other *Session) bool {
+		return other == session
+	})
+	if s.Client == session.Name {
+		s.Client = ""
+	}
+}
+
+// window returns a target's window by ID, index or name, or the session's
+// active window.
+func (s *Server) window(target tmux.Target) (*Session, *Window, error) {
+	session, err := s.findSession(target.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, w := range session.Windows {
+		switch target.Window {
+		case "":
+			if w.Active {
+				return session, w, nil
+			}
+		case w.ID, w.Name, strconv.Itoa(w.Index):
+			return session, w, nil
+		}
+	}
+	return nil, nil, &tmux.Error{
+		Reason: tmux.ErrWindowNotFound,
+		Err:    fmt.Errorf("%w window: %s", ErrNotFound, target.Get()),
+	}
+}
+
+// pane returns a target's pane by ID or index, or the window's active pane.
+func (s *Server) pane(target tmux.Target) (*Window, *Pane, error) {
+	session, w, err := s.window(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target.Pane == "" {
+		return w, activePane(w), nil
+	}
+	base := s.baseIndex(session, "pane-base-index")
+	for i, p := range w.Panes {
+		if target.Pane == p.ID || target.Pane == strconv.Itoa(base+i) {
+			return w, p, nil
+		}
+	}
+	return nil, nil, &tmux.Error{
+		Reason: tmux.ErrPaneNotFound,
+		Err:    fmt.Errorf("%w pane: %s", ErrNotFound, target.Get()),
+	}
+}
+
+// scoped returns the options and hooks of a target in a scope.
+func (s *Server) scoped(
+	target tmux.Target,
+	scope tmux.OptionScope,
+) (map[string]string, map[string]string, error) {
+	switch scope {
+	case tmux.OptionScopeServer:
+		return s.Options, s.Hooks, nil
+	case tmux.OptionScopeWindow:
+		_, w, err := s.window(target)
+		if err != nil {
+			return nil, nil, err
+		}
+		return w.Options, w.Hooks, nil
+	case tmux.OptionScopePane:
+		_, p, err := s.pane(target)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p.Options, p.Hooks, nil
+	default:
+		session, err := s.findSession(target.Session)
+		if err != nil {
+			return nil, nil, err
+		}
+		return session.Options, session.Hooks, nil
+	}
+}
+
+// activePane returns a window's active pane.
+func activePane(w *Window) *Pane {
+	for _, p := range w.Panes {
+		if p.Active {
+			return p
+		}
+	}
+	return w.Panes[0]
+}
@@ -0,0 +1,80 @@
+package tmuxtest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/tmux"
+	"github.com/rafi/jig/pkg/tmux/tmuxtest"
+)
+
+func TestServer(t *testing.T) {
+	server := tmuxtest.NewServer()
+	server.Shell = "zsh"
+
+	_, err := server.NewSession("ses", "/tmp", "editor", "vim", "main.go")
+	require.NoError(t, err)
+	_, err = server.NewSession("ses", "/tmp", "")
+	assert.ErrorIs(t, err, tmuxtest.ErrDuplicate)
+	assert.ErrorIs(t, err, tmux.ErrDuplicateSession)
+
+	// Windows are created at the first free index, after the base-index.
+	target := tmux.Target{Session: "ses"}
+	require.NoError(t, server.SetOption(target, tmux.OptionScopeSession, "base-index", "1"))
+	window, err := server.NewWindow(target, "", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "@1", window)
+	assert.Equal(t, 1, server.Window(tmux.Target{Session: "ses", Window: "@1"}).Index)
+
+	// New panes are inserted after the split pane.
+	target.Window = "editor"
+	first, err := server.NewPane(target, "/var", "horizontal", "30%", nil)
+	require.NoError(t, err)
+	second, err := server.NewPane(target, "", "vertical", "", nil)
+	require.NoError(t, err)
+	_, err = server.NewPane(target, "", "vertical", "half", nil)
+	assert.ErrorIs(t, err, tmux.ErrInvalidSize)
+
+	require.NoError(t, server.SelectPane(tmux.Target{Session: "ses", Window: "editor", Pane: "2"}))
+	require.NoError(t, server.SetPaneTitle(tmux.Target{Session: "ses", Window: "editor", Pane: first}, "logs"))
+	require.NoError(t, server.SendKeys(tmux.Target{Session: "ses", Window: "editor"}, "tail -f log"))
+	index, err := server.PaneIndex(tmux.Target{Session: "ses", Window: "editor"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, index)
+
+	panes, err := server.ListPanes(tmux.Target{Session: "ses", Window: "editor"})
+	require.NoError(t, err)
+	assert.Equal(t, []tmux.TmuxPane{
+		{Path: "/tmp", Command: "vim"},
+		{Path: "/tmp", Command: "zsh"},
+		{Path: "/var", Command: "zsh", Title: "logs"},
+	}, panes)
+	assert.Equal(t, []string{"tail -f log"}, server.Pane(tmux.Target{Session: "ses", Window: "@0", Pane: first}).Keys)
+	assert.Equal(t, "vertical", server.Pane(tmux.Target{Session: "ses", Window: "@0", Pane: second}).Split)
+
+	windows, err := server.ListWindows(tmux.Target{Session: "ses"})
+	require.NoError(t, err)
+	assert.Equal(t, []tmux.TmuxWindow{
+		{ID: "@0", Name: "editor", Path: "/var"},
+		{ID: "@1", Name: "zsh", Path: "/tmp"},
+	}, windows)
+
+	// Killing the last window kills the session.
+	require.NoError(t, server.KillWindow(tmux.Target{Session: "ses", Window: "0"}))
+	require.NoError(t, server.KillWindow(tmux.Target{Session: "ses", Window: "1"}))
+	assert.False(t, server.SessionExists("ses"))
+	_, err = server.StopSession(tmux.Target{Session: "ses"})
+	assert.ErrorIs(t, err, tmuxtest.ErrNotFound)
+	assert.ErrorIs(t, err, tmux.ErrSessionNotFound)
+}
+
+func TestServerVersion(t *testing.T) {
+	server := tmuxtest.NewServer()
+	assert.True(t, server.Supports(tmux.FeatureControlFlags))
+
+	server.Version = &tmux.Version{Major: 3, Minor: 1}
+	assert.True(t, server.Supports(tmux.FeaturePercentSizes))
+	assert.ErrorIs(t, server.Require(tmux.FeaturePaneHooks), tmux.ErrUnsupportedVersion)
+}
@@ -14,9 +14,21 @@ const (
 	LayoutEvenVertical   string = "even-vertical"
 )
 
+type OptionScope string
+
+const (
+	OptionScopeServer  OptionScope = "server"
+	OptionScopeSession OptionScope = "session"
+	OptionScopeWindow  OptionScope = "window"
+	OptionScopePane    OptionScope = "pane"
+)
+
 var (
 	ErrInvalidSplitType = errors.New("invalid split type")
+	ErrInvalidSize      = errors.New("invalid pane size")
+	ErrInvalidLayout    = errors.New("invalid layout")
 	ErrInvalidFormat    = errors.New("invalid shell output format")
+	ErrUnknownPane      = errors.New("unknown pane")
 )
 
 type Target struct {
@@ -54,7 +66,13 @@ type TmuxWindow struct {
 	Path   string `format:"pane_current_path"`
 }
 
+type TmuxWindowSize struct {
+	Width  int `format:"window_width"`
+	Height int `format:"window_height"`
+}
+
 type TmuxPane struct {
 	Path    string `format:"pane_current_path"`
 	Command string `format:"pane_current_command"`
+	Title   string `format:"pane_title"`
 }
@@ -0,0 +1,126 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is a tmux release, e.g. 3.3 for "tmux 3.3a".
+type Version struct {
+	Major int
+	Minor int
+}
+
+// Feature is a capability of tmux, and the release which introduced it.
+type Feature struct {
+	Name  string
+	Since Version
+}
+
+var (
+	FeaturePaneTitles   = Feature{"pane titles", Version{2, 6}}
+	FeatureEnv          = Feature{"per-pane env", Version{3, 0}}
+	FeaturePaneOptions  = Feature{"per-pane options", Version{3, 0}}
+	FeaturePercentSizes = Feature{"percentage sizes", Version{3, 1}}
+	FeaturePaneHooks    = Feature{"per-pane hooks", Version{3, 2}}
+	FeaturePopup        = Feature{"popups", Version{3, 2}}
+	FeatureControlFlags = Feature{"control mode flags", Version{3, 2}}
+)
+
+// latestVersion is assumed for development builds, and when the version
+// can't be detected.
+var latestVersion = Version{Major: math.MaxInt32}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+var ErrUnsupportedVersion = errors.New("unsupported tmux version")
+
+// ParseVersion parses the output of `tmux -V`, e.g. "tmux 3.3a",
+// "tmux next-3.4" or "tmux master".
+func ParseVersion(s string) (Version, error) {
+	_, release, _ := strings.Cut(strings.TrimSpace(s), " ")
+	if release == "master" {
+		return latestVersion, nil
+	}
+	match := versionPattern.FindStringSubmatch(release)
+	if match == nil {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return Version{major, minor}, nil
+}
+
+// AtLeast returns true if the version is the same or newer than another.
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+// Require returns an error if the version doesn't support a feature.
+func (v Version) Require(f Feature) error {
+	if !v.AtLeast(f.Since) {
+		return fmt.Errorf("%w: requires tmux ≥ %s for %s, found %s",
+			ErrUnsupportedVersion, f.Since, f.Name, v)
+	}
+	return nil
+}
+
+func (v Version) String() string {
+	if v == latestVersion {
+		return "master"
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// VersionCache detects the version of each tmux command once.
+type VersionCache struct {
+	mu       sync.Mutex
+	versions map[string]Version
+}
+
+func NewVersionCache() *VersionCache {
+	return &VersionCache{versions: map[string]Version{}}
+}
+
+// Version returns the version of the client's tmux command, detected once
+// per command if the client has a version cache. Otherwise, or if it can't
+// be detected, all features are assumed to be supported.
+func (t TmuxClient) Version() Version {
+	if t.Versions == nil {
+		return latestVersion
+	}
+	t.Versions.mu.Lock()
+	defer t.Versions.mu.Unlock()
+	if v, ok := t.Versions.versions[t.Bin]; ok {
+		return v
+	}
+
+	v := latestVersion
+	out, err := t.Cmd.Exec(exec.Command(t.Bin, "-V"))
+	if err == nil {
+		if parsed, err := ParseVersion(out); err == nil {
+			v = parsed
+		}
+	}
+	t.Versions.versions[t.Bin] = v
+	return v
+}
+
+// Supports returns true if the client's tmux supports a feature.
+func (t TmuxClient) Supports(f Feature) bool {
+	return t.Version().AtLeast(f.Since)
+}
+
+// Require returns an error if the client's tmux doesn't support a feature.
+func (t TmuxClient) Require(f Feature) error {
+	return t.Version().Require(f)
+}
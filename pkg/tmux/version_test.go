@@ -0,0 +1,72 @@
+package tmux_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafi/jig/pkg/tmux"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]tmux.Version{
+		"tmux 3.3a":      {Major: 3, Minor: 3},
+		"tmux 2.9\n":     {Major: 2, Minor: 9},
+		"tmux next-3.5":  {Major: 3, Minor: 5},
+		"tmux 3.10":      {Major: 3, Minor: 10},
+		"tmate 2.4.0":    {Major: 2, Minor: 4},
+		"tmux openbsd-7": {},
+	}
+	for s, expected := range tests {
+		v, err := tmux.ParseVersion(s)
+		if expected == (tmux.Version{}) {
+			assert.ErrorIs(t, err, tmux.ErrInvalidFormat, s)
+			continue
+		}
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, v, s)
+	}
+
+	v, err := tmux.ParseVersion("tmux master")
+	require.NoError(t, err)
+	assert.True(t, v.AtLeast(tmux.Version{Major: 99}))
+	assert.Equal(t, "master", v.String())
+}
+
+func TestVersionFeatures(t *testing.T) {
+	commander := &outputCommander{outputs: []string{"tmux 2.5"}}
+	client := tmux.TmuxClient{Bin: "tmux", Cmd: commander, Versions: tmux.NewVersionCache()}
+	target := tmux.Target{Session: "ses", Window: "@1"}
+
+	// Older releases split by percentage with -p, and have no pane titles.
+	_, err := client.NewPane(target, "", "vertical", "40%", nil)
+	require.NoError(t, err)
+	assert.NoError(t, client.SetPaneTitle(target, "title"))
+	assert.False(t, client.Supports(tmux.FeaturePaneTitles))
+	assert.EqualError(t, client.Require(tmux.FeaturePaneOptions),
+		"unsupported tmux version: requires tmux ≥ 3.0 for per-pane options, found 2.5")
+
+	// The version is detected once.
+	assert.Equal(t, []string{
+		"tmux -V",
+		"tmux split-window -Pd -t ses:@1 -v -p 40 -F #{pane_id}",
+	}, commander.commands)
+
+	// Without a cache, all features are assumed to be supported.
+	client.Versions = nil
+	assert.NoError(t, client.Require(tmux.FeatureControlFlags))
+}
+
+func TestNewPaneSplit(t *testing.T) {
+	commander := &outputCommander{}
+	client := tmux.TmuxClient{Bin: "tmux", Cmd: commander}
+	target := tmux.Target{Session: "ses", Window: "@1"}
+
+	// An empty split type leaves the split to tmux, and unknown ones fail.
+	_, err := client.NewPane(target, "", "", "", nil)
+	require.NoError(t, err)
+	_, err = client.NewPane(target, "", "diagonal", "", nil)
+	assert.ErrorIs(t, err, tmux.ErrInvalidSplitType)
+	assert.Equal(t, []string{"tmux split-window -Pd -t ses:@1 -F #{pane_id}"}, commander.commands)
+}
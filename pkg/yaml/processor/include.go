@@ -3,6 +3,7 @@ package processor
 import (
 	"errors"
 	"os"
+	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 
@@ -12,39 +13,50 @@ import (
 // used for loading included files
 type Fragment struct {
 	content *yaml.Node
+
+	// dir is the directory of the included file, which its includes are
+	// relative to.
+	dir string
 }
 
 func (f *Fragment) UnmarshalYAML(value *yaml.Node) error {
 	var err error
 	// process includes in fragments
-	f.content, err = resolveIncludes(value)
+	f.content, err = resolveIncludes(value, f.dir)
 	return err
 }
 
 type IncludeProcessor struct {
 	Out interface{}
+
+	// Dir is the directory included files are relative to, e.g. of the
+	// config file. Defaults to the current directory.
+	Dir string
 }
 
 func (i *IncludeProcessor) UnmarshalYAML(value *yaml.Node) error {
-	resolved, err := resolveIncludes(value)
+	resolved, err := resolveIncludes(value, i.Dir)
 	if err != nil {
 		return err
 	}
 	return resolved.Decode(i.Out)
 }
 
-func resolveIncludes(node *yaml.Node) (*yaml.Node, error) {
+func resolveIncludes(node *yaml.Node, dir string) (*yaml.Node, error) {
 	if node.Tag == "!include" {
 		if node.Kind != yaml.ScalarNode {
 			return nil, errors.New("!include on a non-scalar node")
 		}
-		includePath := shell.ExpandPath(node.Value)
+		includePath := shell.ResolvePath(node.Value, dir)
 		file, err := os.ReadFile(includePath)
 		if err != nil {
 			return nil, err
 		}
-		var f Fragment
+		f := Fragment{dir: filepath.Dir(includePath)}
 		err = yaml.Unmarshal(file, &f)
+		if err != nil {
+			return nil, err
+		}
 		f.content.Content = append(
 			f.content.Content,
 			&yaml.Node{
@@ -53,16 +65,16 @@ func resolveIncludes(node *yaml.Node) (*yaml.Node, error) {
 			},
 			&yaml.Node{
 				Kind:  yaml.ScalarNode,
-				Value: node.Value,
+				Value: includePath,
 				Style: yaml.DoubleQuotedStyle,
 			},
 		)
-		return f.content, err
+		return f.content, nil
 	}
 	if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
 		var err error
 		for i := range node.Content {
-			node.Content[i], err = resolveIncludes(node.Content[i])
+			node.Content[i], err = resolveIncludes(node.Content[i], dir)
 			if err != nil {
 				return nil, err
 			}